@@ -0,0 +1,183 @@
+// Package app assembles the API gateway from its configuration. The binary
+// in cmd serves it on a real listener; end-to-end tests serve it in-process.
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/auth"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/cache"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/client"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/config"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/controller"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/metrics"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/middleware"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/openapi"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/proxy"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/usecase"
+	sharedconfig "github.com/rrxshxd/assignment1_advProg2/config"
+	"github.com/rrxshxd/assignment1_advProg2/observability/health"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	sharedopenapi "github.com/rrxshxd/assignment1_advProg2/openapi"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net/http"
+)
+
+type Config = config.Config
+
+// DefaultConfig returns a Config holding only the defaults, for callers that
+// build their configuration in code rather than loading it.
+func DefaultConfig() (*Config, error) {
+	cfg := &Config{}
+	if err := sharedconfig.Defaults(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// App is a wired gateway. The handler is not bound to a listener; that is up
+// to the caller.
+type App struct {
+	HTTP http.Handler
+
+	userClient *client.UserClient
+}
+
+// New wires the gateway. userDialOptions are added to those used to reach
+// the user service, which lets tests dial it in memory.
+func New(ctx context.Context, cfg *Config, logger *slog.Logger, userDialOptions ...grpc.DialOption) (*App, error) {
+	spec, err := openapi.Spec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	validator, err := sharedopenapi.Validator(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request validator: %w", err)
+	}
+
+	forwarding, err := proxy.NewForwarding(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	gatewayController := controller.NewGatewayController(
+		cfg.InventoryServiceURL,
+		cfg.OrderServiceURL,
+		cache.NewResponseCache(cfg.CacheMaxEntries),
+		forwarding,
+	)
+
+	userClient, err := client.NewUserClient(cfg.UserServiceAddr, userDialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	app := &App{userClient: userClient}
+	tokenVerifier := auth.NewTokenVerifier(cfg.JWTSecret)
+
+	orderClient := client.NewOrderClient(cfg.OrderServiceURL, &http.Client{
+		Timeout:   cfg.AggregationTimeout,
+		Transport: otelhttp.NewTransport(metrics.InstrumentTransport("order_service", nil)),
+	})
+	inventoryClient := client.NewInventoryClient(cfg.InventoryServiceURL, &http.Client{
+		Timeout:   cfg.AggregationTimeout,
+		Transport: otelhttp.NewTransport(metrics.InstrumentTransport("inventory_service", nil)),
+	})
+	orderDetailsUseCase := usecase.NewOrderDetailsUseCase(
+		orderClient,
+		inventoryClient,
+		userClient,
+		cfg.AggregationTimeout,
+	)
+	orderDetailsController := controller.NewOrderDetailsController(orderDetailsUseCase)
+	healthController := health.NewController(map[string]health.Check{
+		"inventory_service": inventoryClient.Check,
+		"order_service":     orderClient.Check,
+		"user_service":      userClient.Check,
+	})
+
+	userTranscoder, err := controller.NewUserTranscoder(ctx, userClient.Service())
+	if err != nil {
+		userClient.Close()
+		return nil, fmt.Errorf("failed to create user transcoder: %w", err)
+	}
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		userClient.Close()
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	router.Use(
+		otelgin.Middleware("api_gateway"),
+		middleware.RequestID(forwarding),
+		logging.HTTPMiddleware(logger),
+		logging.Recovery(),
+		metrics.HTTPMiddleware(),
+		middleware.StripIdentityHeaders(),
+		middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+			HSTSMaxAge:            cfg.HSTSMaxAge,
+			ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		}),
+		middleware.CORS(middleware.CORSConfig{
+			AllowedOrigins:   cfg.CORSAllowedOrigins,
+			AllowedMethods:   cfg.CORSAllowedMethods,
+			AllowedHeaders:   cfg.CORSAllowedHeaders,
+			ExposedHeaders:   []string{"ETag", "Last-Modified"},
+			AllowCredentials: cfg.CORSAllowCredentials,
+			MaxAge:           cfg.CORSMaxAge,
+		}),
+	)
+
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/openapi.json", sharedopenapi.Handler(spec))
+	router.GET("/docs", sharedopenapi.UI(spec.Info.Title, "/openapi.json"))
+
+	inventory := router.Group("/inventory",
+		middleware.BodyLimit(cfg.InventoryMaxBodyBytes),
+		middleware.Authenticate(userClient, tokenVerifier, "inventory", true),
+		validator,
+	)
+	{
+		inventory.GET("/products", gatewayController.ProxyInventory)
+		inventory.POST("/products", gatewayController.ProxyInventory)
+		inventory.POST("/products/create", gatewayController.ProxyInventory)
+		inventory.GET("/products/:id", gatewayController.ProxyInventory)
+		inventory.PATCH("/products/:id", gatewayController.ProxyInventory)
+		inventory.PUT("/products/:id", gatewayController.ProxyInventory)
+		inventory.DELETE("/products/:id", gatewayController.ProxyInventory)
+	}
+
+	orders := router.Group("/orders",
+		middleware.BodyLimit(cfg.OrderMaxBodyBytes),
+		middleware.Authenticate(userClient, tokenVerifier, "orders", false),
+		validator,
+	)
+	{
+		orders.POST("/", gatewayController.ProxyOrders)
+		orders.GET("/:id", gatewayController.ProxyOrders)
+		orders.PATCH("/:id", gatewayController.ProxyOrders)
+	}
+
+	api := router.Group("/api", middleware.Authenticate(userClient, tokenVerifier, "orders", false), validator)
+	{
+		api.GET("/orders/:id/details", orderDetailsController.GetOrderDetails)
+	}
+
+	v1 := router.Group("/v1", middleware.BodyLimit(cfg.UserMaxBodyBytes))
+	{
+		v1.Any("/*path", gin.WrapH(userTranscoder))
+	}
+	app.HTTP = router
+
+	return app, nil
+}
+
+// Close releases the connection to the user service.
+func (a *App) Close() error {
+	return a.userClient.Close()
+}
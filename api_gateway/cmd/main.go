@@ -1,32 +1,77 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/app"
 	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/config"
-	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/controller"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"github.com/rrxshxd/assignment1_advProg2/observability/tracing"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
+	if err != nil {
+		slog.Error("Invalid log level", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "api_gateway", tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+		StdoutFile:   cfg.TracingFile,
+	})
+	if err != nil {
+		fatal(logger, "Failed to set up tracing", err)
+	}
+
+	application, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		fatal(logger, "Failed to start API gateway", err)
+	}
+	defer application.Close()
 
-	gatewayController := controller.NewGatewayController(
-		cfg.InventoryServiceURL,
-		cfg.OrderServiceURL,
-	)
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: application.HTTP}
+	go func() {
+		logger.Info("API gateway is running", "port", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal(logger, "Failed to serve HTTP", err)
+		}
+	}()
 
-	router := gin.Default()
+	<-ctx.Done()
+	logger.Info("Shutting down, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout.String())
 
-	inventory := router.Group("/inventory")
-	{
-		inventory.GET("/products", gatewayController.ProxyInventory)
-		inventory.POST("/products", gatewayController.ProxyInventory)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown", "error", err)
 	}
 
-	orders := router.Group("/orders")
-	{
-		orders.POST("/", gatewayController.ProxyOrders)
-		orders.GET("/:id", gatewayController.ProxyOrders)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Tracing shutdown", "error", err)
 	}
+}
 
-	router.Run(":" + cfg.Port)
+// fatal logs err and exits. Deferred calls do not run.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
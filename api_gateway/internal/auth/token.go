@@ -0,0 +1,39 @@
+// Package auth verifies the access tokens issued by the user service, which
+// signs them with a secret shared with the gateway.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type TokenVerifier struct {
+	secret []byte
+}
+
+func NewTokenVerifier(secret string) *TokenVerifier {
+	return &TokenVerifier{secret: []byte(secret)}
+}
+
+func (v *TokenVerifier) VerifyToken(token string) (*entity.TokenIdentity, error) {
+	var claims struct {
+		UserID uint     `json:"user_id"`
+		Scopes []string `json:"scopes"`
+		jwt.RegisteredClaims
+	}
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.UserID == 0 {
+		return nil, fmt.Errorf("%w: missing user_id claim", ErrInvalidToken)
+	}
+
+	return &entity.TokenIdentity{UserID: claims.UserID, Scopes: claims.Scopes}, nil
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Entry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	ETag       string
+	ExpiresAt  time.Time
+}
+
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+type ResponseCache struct {
+	mu         sync.RWMutex
+	entries    map[string]*Entry
+	maxEntries int
+}
+
+func NewResponseCache(maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		entries:    make(map[string]*Entry),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *ResponseCache) Get(key string) (*Entry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *ResponseCache) Set(key string, entry *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evictLocked()
+	}
+	c.entries[key] = entry
+}
+
+func (c *ResponseCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+func (c *ResponseCache) InvalidatePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// evictLocked drops the entry closest to expiry; callers must hold c.mu.
+func (c *ResponseCache) evictLocked() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if oldestKey == "" || entry.ExpiresAt.Before(oldest) {
+			oldestKey = key
+			oldest = entry.ExpiresAt
+		}
+	}
+	delete(c.entries, oldestKey)
+}
+
+type Directives struct {
+	NoStore   bool
+	NoCache   bool
+	Private   bool
+	Public    bool
+	MaxAge    time.Duration
+	HasMaxAge bool
+}
+
+func ParseCacheControl(header string) Directives {
+	var d Directives
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			d.NoStore = true
+		case "no-cache":
+			d.NoCache = true
+		case "private":
+			d.Private = true
+		case "public":
+			d.Public = true
+		case "max-age", "s-maxage":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			// s-maxage takes precedence over max-age for shared caches.
+			if !d.HasMaxAge || strings.EqualFold(name, "s-maxage") {
+				d.MaxAge = time.Duration(seconds) * time.Second
+				d.HasMaxAge = true
+			}
+		}
+	}
+	return d
+}
+
+// WantsRevalidation reports whether a request forbids answering from a stored
+// entry without checking it with the origin first.
+func WantsRevalidation(header http.Header) bool {
+	directives := ParseCacheControl(header.Get("Cache-Control"))
+	if directives.NoCache || (directives.HasMaxAge && directives.MaxAge == 0) {
+		return true
+	}
+	return header.Get("Cache-Control") == "" && strings.EqualFold(strings.TrimSpace(header.Get("Pragma")), "no-cache")
+}
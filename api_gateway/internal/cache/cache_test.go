@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	now := time.Now()
+	c := NewResponseCache(2)
+
+	c.Set("/inventory/products/1", &Entry{ExpiresAt: now.Add(time.Minute)})
+	c.Set("/inventory/products?page=1", &Entry{ExpiresAt: now.Add(time.Second)})
+	if _, ok := c.Get("/inventory/products/1"); !ok {
+		t.Fatal("Get after Set found nothing")
+	}
+
+	c.Set("/inventory/products/2", &Entry{ExpiresAt: now.Add(time.Hour)})
+	if _, ok := c.Get("/inventory/products?page=1"); ok {
+		t.Error("a full cache kept the entry closest to expiry")
+	}
+	if _, ok := c.Get("/inventory/products/2"); !ok {
+		t.Error("a full cache did not store the new entry")
+	}
+
+	c.Set("/inventory/products/1", &Entry{ExpiresAt: now.Add(2 * time.Hour)})
+	if _, ok := c.Get("/inventory/products/2"); !ok {
+		t.Error("replacing an entry evicted another one")
+	}
+
+	c.Delete("/inventory/products/1")
+	if _, ok := c.Get("/inventory/products/1"); ok {
+		t.Error("Get after Delete found the entry")
+	}
+
+	c.Set("/inventory/products/3", &Entry{})
+	c.InvalidatePrefix("/inventory/products")
+	for _, key := range []string{"/inventory/products/2", "/inventory/products/3"} {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("InvalidatePrefix kept %s", key)
+		}
+	}
+}
+
+func TestEntryFresh(t *testing.T) {
+	now := time.Now()
+	entry := &Entry{ExpiresAt: now.Add(time.Second)}
+	if !entry.Fresh(now) {
+		t.Error("entry before its expiry is not fresh")
+	}
+	if entry.Fresh(now.Add(time.Second)) {
+		t.Error("entry at its expiry is still fresh")
+	}
+}
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Directives
+	}{
+		{"", Directives{}},
+		{"no-store", Directives{NoStore: true}},
+		{"No-Cache, PRIVATE", Directives{NoCache: true, Private: true}},
+		{"public, max-age=60", Directives{Public: true, MaxAge: time.Minute, HasMaxAge: true}},
+		{`max-age="30"`, Directives{MaxAge: 30 * time.Second, HasMaxAge: true}},
+		{"max-age=0", Directives{HasMaxAge: true}},
+		{"s-maxage=10, max-age=60", Directives{MaxAge: 10 * time.Second, HasMaxAge: true}},
+		{"max-age=60, s-maxage=10", Directives{MaxAge: 10 * time.Second, HasMaxAge: true}},
+		{"max-age=-1", Directives{}},
+		{"max-age=soon", Directives{}},
+	}
+	for _, tt := range tests {
+		if got := ParseCacheControl(tt.header); got != tt.want {
+			t.Errorf("ParseCacheControl(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestWantsRevalidation(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		pragma       string
+		want         bool
+	}{
+		{"", "", false},
+		{"max-age=60", "", false},
+		{"no-cache", "", true},
+		{"max-age=0", "", true},
+		{"", "no-cache", true},
+		{"max-age=60", "no-cache", false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.cacheControl != "" {
+			header.Set("Cache-Control", tt.cacheControl)
+		}
+		if tt.pragma != "" {
+			header.Set("Pragma", tt.pragma)
+		}
+		if got := WantsRevalidation(header); got != tt.want {
+			t.Errorf("WantsRevalidation(Cache-Control %q, Pragma %q) = %v, want %v", tt.cacheControl, tt.pragma, got, tt.want)
+		}
+	}
+}
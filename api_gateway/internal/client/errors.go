@@ -0,0 +1,9 @@
+package client
+
+import "errors"
+
+var (
+	ErrNotFound        = errors.New("resource not found")
+	ErrInvalidAPIKey   = errors.New("invalid api key")
+	ErrAPIKeyForbidden = errors.New("api key lacks required scope")
+)
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"net/http"
+)
+
+func (c *InventoryClient) Check(ctx context.Context) error {
+	return checkLiveness(ctx, c.httpClient, c.baseURL)
+}
+
+func (c *OrderClient) Check(ctx context.Context) error {
+	return checkLiveness(ctx, c.httpClient, c.baseURL)
+}
+
+// Check asks the user service's grpc.health.v1 service for its overall status.
+func (c *UserClient) Check(ctx context.Context) error {
+	resp, err := grpc_health_v1.NewHealthClient(c.conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("user service is %s", resp.Status)
+	}
+	return nil
+}
+
+func checkLiveness(ctx context.Context, httpClient *http.Client, baseURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/healthz", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	"net/http"
+)
+
+type InventoryClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewInventoryClient(baseURL string, httpClient *http.Client) *InventoryClient {
+	return &InventoryClient{baseURL: baseURL, httpClient: httpClient}
+}
+
+func (c *InventoryClient) GetProduct(ctx context.Context, id uint) (*entity.Product, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/products/%d", c.baseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var product entity.Product
+	if err := doJSON(c.httpClient, req, &product); err != nil {
+		return nil, fmt.Errorf("failed to get product %d: %w", id, err)
+	}
+
+	return &product, nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	orderdto "github.com/rrxshxd/assignment1_advProg2/order_service/dto"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type OrderClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewOrderClient(baseURL string, httpClient *http.Client) *OrderClient {
+	return &OrderClient{baseURL: baseURL, httpClient: httpClient}
+}
+
+// GetOrder loads an order on behalf of caller, whom the order service checks
+// against the order's owner.
+func (c *OrderClient) GetOrder(ctx context.Context, id uint, caller *entity.Caller) (*entity.Order, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/orders/%d", c.baseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(orderdto.UserIDHeader, strconv.FormatUint(uint64(caller.UserID), 10))
+	req.Header.Set(orderdto.ScopesHeader, strings.Join(caller.Scopes, ","))
+
+	var order entity.Order
+	if err := doJSON(c.httpClient, req, &order); err != nil {
+		return nil, fmt.Errorf("failed to get order %d: %w", id, err)
+	}
+
+	return &order, nil
+}
+
+func doJSON(httpClient *http.Client, req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	if id := requestid.FromContext(req.Context()); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
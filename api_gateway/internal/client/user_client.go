@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/metrics"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	"github.com/rrxshxd/assignment1_advProg2/proto/user"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type UserClient struct {
+	conn   *grpc.ClientConn
+	client user.UserServiceClient
+}
+
+// NewUserClient connects to the user service at addr. opts are applied after
+// the defaults, so they can replace the dialer or credentials.
+func NewUserClient(addr string, opts ...grpc.DialOption) (*UserClient, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(requestid.UnaryClientInterceptor, metrics.UnaryClientInterceptor("user_service")),
+	}, opts...)
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user service client: %w", err)
+	}
+
+	return &UserClient{conn: conn, client: user.NewUserServiceClient(conn)}, nil
+}
+
+func (c *UserClient) Service() user.UserServiceClient {
+	return c.client
+}
+
+func (c *UserClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetUserProfile forwards the caller's bearer token, since the user service
+// only returns a profile to its owner.
+func (c *UserClient) GetUserProfile(ctx context.Context, userID uint, authorization string) (*entity.UserProfile, error) {
+	if authorization != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", authorization)
+	}
+
+	profile, err := c.client.GetUserProfile(ctx, &user.GetUserProfileRequest{UserId: uint64(userID)})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get user profile %d: %w", userID, err)
+	}
+
+	result := &entity.UserProfile{
+		ID:       uint(profile.Id),
+		Email:    profile.Email,
+		Username: profile.Username,
+	}
+	for _, addr := range profile.Addresses {
+		address := entity.Address{
+			Street:     addr.Street,
+			City:       addr.City,
+			State:      addr.State,
+			PostalCode: addr.PostalCode,
+			Country:    addr.Country,
+		}
+		result.Addresses = append(result.Addresses, address)
+	}
+
+	// Without an address marked as default there is no shipping address.
+	for i, addr := range profile.Addresses {
+		if addr.IsDefault {
+			result.Default = &result.Addresses[i]
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (c *UserClient) ValidateAPIKey(ctx context.Context, key, requiredScope string) (*entity.APIKeyIdentity, error) {
+	resp, err := c.client.ValidateAPIKey(ctx, &user.ValidateAPIKeyRequest{Key: key, RequiredScope: requiredScope})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated, codes.InvalidArgument:
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKey, status.Convert(err).Message())
+		case codes.PermissionDenied:
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyForbidden, status.Convert(err).Message())
+		}
+		return nil, fmt.Errorf("failed to validate api key: %w", err)
+	}
+
+	return &entity.APIKeyIdentity{
+		KeyID:  uint(resp.KeyId),
+		UserID: uint(resp.UserId),
+		Scopes: resp.Scopes,
+	}, nil
+}
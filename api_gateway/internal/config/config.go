@@ -1,24 +1,71 @@
 package config
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	sharedconfig "github.com/rrxshxd/assignment1_advProg2/config"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+)
 
 type Config struct {
-	Port                string
-	InventoryServiceURL string
-	OrderServiceURL     string
+	Port                string        `yaml:"port" default:"8080" validate:"required"`
+	InventoryServiceURL string        `yaml:"inventory_service_url" default:"http://localhost:8081" validate:"required"`
+	OrderServiceURL     string        `yaml:"order_service_url" default:"http://localhost:8082" validate:"required"`
+	UserServiceAddr     string        `yaml:"user_service_addr" default:"localhost:50051" validate:"required"`
+	JWTSecret           string        `yaml:"jwt_secret" validate:"required" usage:"HMAC key the user service signs access tokens with"`
+	CacheMaxEntries     int           `yaml:"cache_max_entries" default:"1000" validate:"min=0"`
+	AggregationTimeout  time.Duration `yaml:"aggregation_timeout" default:"5s" validate:"min=1ms"`
+	TrustedProxies      []string      `yaml:"trusted_proxies" usage:"comma-separated CIDRs or IPs"`
+
+	CORSAllowedOrigins   []string      `yaml:"cors_allowed_origins"`
+	CORSAllowedMethods   []string      `yaml:"cors_allowed_methods" default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORSAllowedHeaders   []string      `yaml:"cors_allowed_headers" default:"Authorization,Content-Type,X-API-Key,If-None-Match"`
+	CORSAllowCredentials bool          `yaml:"cors_allow_credentials" default:"false"`
+	CORSMaxAge           time.Duration `yaml:"cors_max_age" default:"10m"`
+
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age" default:"8760h"`
+	ContentSecurityPolicy string        `yaml:"content_security_policy" default:"default-src 'none'; frame-ancestors 'none'"`
+
+	InventoryMaxBodyBytes int64 `yaml:"inventory_max_body_bytes" default:"1048576" validate:"min=1"`
+	OrderMaxBodyBytes     int64 `yaml:"order_max_body_bytes" default:"262144" validate:"min=1"`
+	UserMaxBodyBytes      int64 `yaml:"user_max_body_bytes" default:"65536" validate:"min=1"`
+
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" default:"15s"`
+
+	TracingExporter string `yaml:"tracing_exporter" default:"none" validate:"oneof=none otlp stdout"`
+	OTLPEndpoint    string `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"localhost:4317"`
+	OTLPInsecure    bool   `yaml:"otlp_insecure" env:"OTEL_EXPORTER_OTLP_INSECURE" default:"true"`
+	TracingFile     string `yaml:"tracing_file"`
+
+	LogLevel string `yaml:"log_level" default:"info" validate:"oneof=debug info warn error"`
 }
 
-func LoadConfig() *Config {
-	return &Config{
-		Port:                getEnv("PORT", "8080"),
-		InventoryServiceURL: getEnv("INVENTORY_SERVICE_URL", "http://localhost:8081"),
-		OrderServiceURL:     getEnv("ORDER_SERVICE_URL", "http://localhost:8082"),
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := sharedconfig.Load(cfg, os.Args[1:]); err != nil {
+		return nil, err
 	}
+	return cfg, nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// Validate rejects upstream URLs the proxies could not build requests from
+// and a wildcard CORS origin on credentialed requests, which browsers refuse.
+func (c *Config) Validate() error {
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		return errors.New("cors_allowed_origins cannot contain \"*\" while cors_allow_credentials is set; list the origins instead")
+	}
+
+	for name, raw := range map[string]string{
+		"inventory_service_url": c.InventoryServiceURL,
+		"order_service_url":     c.OrderServiceURL,
+	} {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s must be an absolute http(s) URL, got %q", name, raw)
+		}
 	}
-	return defaultValue
+	return nil
 }
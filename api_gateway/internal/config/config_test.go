@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{name: "relative inventory URL", modify: func(c *Config) { c.InventoryServiceURL = "/inventory" }, wantErr: true},
+		{name: "non-http order URL", modify: func(c *Config) { c.OrderServiceURL = "ftp://orders" }, wantErr: true},
+		{name: "wildcard origin", modify: func(c *Config) { c.CORSAllowedOrigins = []string{"*"} }},
+		{name: "listed origins with credentials", modify: func(c *Config) {
+			c.CORSAllowedOrigins = []string{"https://shop.example.com"}
+			c.CORSAllowCredentials = true
+		}},
+		{name: "wildcard origin with credentials", modify: func(c *Config) {
+			c.CORSAllowedOrigins = []string{"*"}
+			c.CORSAllowCredentials = true
+		}, wantErr: true},
+		{name: "wildcard among origins with credentials", modify: func(c *Config) {
+			c.CORSAllowedOrigins = []string{"https://shop.example.com", "*"}
+			c.CORSAllowCredentials = true
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				InventoryServiceURL: "http://localhost:8081",
+				OrderServiceURL:     "http://localhost:8082",
+			}
+			tt.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,59 +1,81 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/cache"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/metrics"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/proxy"
+	"github.com/rrxshxd/assignment1_advProg2/httpclient"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
+const inventoryPrefix = "/inventory"
+
 type GatewayController struct {
 	inventoryServiceURL string
 	orderServiceURL     string
+	productCache        *cache.ResponseCache
+	forwarding          *proxy.Forwarding
+	inventoryClient     *http.Client
+	orderClient         *http.Client
 }
 
-func NewGatewayController(inventoryURL, orderURL string) *GatewayController {
+func NewGatewayController(inventoryURL, orderURL string, productCache *cache.ResponseCache, forwarding *proxy.Forwarding) *GatewayController {
 	return &GatewayController{
 		inventoryServiceURL: inventoryURL,
 		orderServiceURL:     orderURL,
+		productCache:        productCache,
+		forwarding:          forwarding,
+		inventoryClient:     &http.Client{Transport: otelhttp.NewTransport(metrics.InstrumentTransport("inventory_service", nil))},
+		orderClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: otelhttp.NewTransport(metrics.InstrumentTransport("order_service", nil)),
+		},
 	}
 }
 
 func (c *GatewayController) ProxyInventory(ctx *gin.Context) {
-	targetURL := c.inventoryServiceURL + ctx.Request.URL.Path
+	targetURL := c.inventoryServiceURL + strings.TrimPrefix(ctx.Request.URL.Path, inventoryPrefix)
+	if ctx.Request.URL.RawQuery != "" {
+		targetURL += "?" + ctx.Request.URL.RawQuery
+	}
 
-	req, err := http.NewRequest(ctx.Request.Method, targetURL, ctx.Request.Body)
+	req, err := http.NewRequestWithContext(ctx.Request.Context(), ctx.Request.Method, targetURL, ctx.Request.Body)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	for key, values := range ctx.Request.Header {
-		for _, value := range values {
-			req.Header.Add(key, value)
-		}
-	}
+	req.Header = c.forwarding.OutboundHeader(ctx.Request)
 
-	// Add X-Forwarded-For
-	if clientIP := ctx.ClientIP(); clientIP != "" {
-		req.Header.Add("X-Forwarded-For", clientIP)
+	if ctx.Request.Method == http.MethodGet && c.productCache != nil {
+		c.proxyCached(ctx, req)
+		return
 	}
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.inventoryClient.Do(req)
 	if err != nil {
-		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		writeUpstreamError(ctx, err)
 		return
 	}
 	defer resp.Body.Close()
 
-	// Copy response
-	for key, values := range resp.Header {
-		for _, value := range values {
-			ctx.Header(key, value)
-		}
+	// Any successful mutation may change list pages as well as the item itself.
+	if c.productCache != nil && ctx.Request.Method != http.MethodHead && resp.StatusCode < 300 {
+		c.productCache.InvalidatePrefix(inventoryPrefix + "/products")
 	}
+
+	// Copy response
+	proxy.CopyResponseHeader(ctx.Writer.Header(), resp.Header)
 	ctx.Status(resp.StatusCode)
 	ctx.Stream(func(w io.Writer) bool {
 		io.Copy(w, resp.Body)
@@ -61,33 +83,134 @@ func (c *GatewayController) ProxyInventory(ctx *gin.Context) {
 	})
 }
 
-func (c *GatewayController) ProxyOrders(ctx *gin.Context) {
-	targetURL := c.orderServiceURL + ctx.Request.URL.Path
+func (c *GatewayController) proxyCached(ctx *gin.Context, req *http.Request) {
+	key := ctx.Request.URL.RequestURI()
+	clientETags := ctx.GetHeader("If-None-Match")
 
-	req, err := http.NewRequest(ctx.Request.Method, targetURL, ctx.Request.Body)
+	entry, found := c.productCache.Get(key)
+	if found && entry.Fresh(time.Now()) && !cache.WantsRevalidation(ctx.Request.Header) {
+		writeCachedEntry(ctx, entry, clientETags)
+		return
+	}
+
+	if found && entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := c.inventoryClient.Do(req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeUpstreamError(ctx, err)
 		return
 	}
+	defer resp.Body.Close()
 
-	req.Header = ctx.Request.Header.Clone()
-	if authToken := ctx.GetHeader("Authorization"); authToken != "" {
-		req.Header.Set("Authorization", authToken)
+	if found && resp.StatusCode == http.StatusNotModified {
+		refreshed := *entry
+		refreshed.ExpiresAt = expiresAt(cache.ParseCacheControl(resp.Header.Get("Cache-Control")))
+		c.productCache.Set(key, &refreshed)
+		writeCachedEntry(ctx, &refreshed, clientETags)
+		return
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
+
+	header := resp.Header.Clone()
+	proxy.RemoveHopHeaders(header)
+	header.Del(requestid.Header)
+
+	entry = &cache.Entry{
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		Body:       body,
+		ETag:       resp.Header.Get("ETag"),
+	}
+
+	directives := cache.ParseCacheControl(resp.Header.Get("Cache-Control"))
+	if isCacheable(req, resp, directives) {
+		entry.ExpiresAt = expiresAt(directives)
+		c.productCache.Set(key, entry)
+	} else if found {
+		c.productCache.Delete(key)
+	}
+
+	writeCachedEntry(ctx, entry, clientETags)
+}
+
+func isCacheable(req *http.Request, resp *http.Response, directives cache.Directives) bool {
+	if resp.StatusCode != http.StatusOK || directives.NoStore || directives.Private {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" && !directives.Public {
+		return false
+	}
+	// Without a lifetime or a validator the entry could never be served again.
+	return directives.MaxAge > 0 || resp.Header.Get("ETag") != ""
+}
+
+func expiresAt(directives cache.Directives) time.Time {
+	if directives.NoCache || !directives.HasMaxAge {
+		return time.Now()
+	}
+	return time.Now().Add(directives.MaxAge)
+}
+
+func writeCachedEntry(ctx *gin.Context, entry *cache.Entry, clientETags string) {
+	for key, values := range entry.Header {
+		for _, value := range values {
+			ctx.Header(key, value)
+		}
+	}
+
+	if entry.StatusCode == http.StatusOK && httpclient.MatchETag(clientETags, entry.ETag) {
+		ctx.Writer.Header().Del("Content-Length")
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.Data(entry.StatusCode, entry.Header.Get("Content-Type"), entry.Body)
+}
+
+func writeUpstreamError(ctx *gin.Context, err error) {
+	logging.FromContext(ctx.Request.Context()).WarnContext(ctx.Request.Context(), "upstream request failed", "error", err)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit),
+		})
+		return
+	}
+	ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+}
+
+func (c *GatewayController) ProxyOrders(ctx *gin.Context) {
+	// The order service registers POST /orders without a trailing slash and
+	// would answer the gateway's POST /orders/ with a redirect.
+	targetURL := c.orderServiceURL + strings.TrimSuffix(ctx.Request.URL.Path, "/")
+
+	req, err := http.NewRequestWithContext(ctx.Request.Context(), ctx.Request.Method, targetURL, ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Header = c.forwarding.OutboundHeader(ctx.Request)
+
+	resp, err := c.orderClient.Do(req)
+	if err != nil {
+		writeUpstreamError(ctx, err)
+		return
+	}
 	defer resp.Body.Close()
 
-	ctx.DataFromReader(
-		resp.StatusCode,
-		resp.ContentLength,
-		resp.Header.Get("Content-Type"),
-		resp.Body,
-		map[string]string{},
-	)
+	proxy.CopyResponseHeader(ctx.Writer.Header(), resp.Header)
+	ctx.Status(resp.StatusCode)
+	ctx.Stream(func(w io.Writer) bool {
+		io.Copy(w, resp.Body)
+		return false
+	})
 }
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/proxy"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProxyOrdersCopiesResponseHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders" {
+			t.Errorf("upstream path = %q, want /orders", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/orders/7")
+		w.Header().Set("Connection", "X-Upstream-Hop")
+		w.Header().Set("X-Upstream-Hop", "1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":7}`))
+	}))
+	defer upstream.Close()
+
+	forwarding, err := proxy.NewForwarding(nil)
+	if err != nil {
+		t.Fatalf("NewForwarding: %v", err)
+	}
+	gateway := NewGatewayController("", upstream.URL, nil, forwarding)
+	router := gin.New()
+	router.POST("/orders/", gateway.ProxyOrders)
+
+	// ctx.Stream needs a real connection rather than a ResponseRecorder.
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/orders/", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST /orders/: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusCreated || string(body) != `{"id":7}` {
+		t.Fatalf("response = %d %s, want 201 with the upstream body", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Location"); got != "/orders/7" {
+		t.Errorf("Location = %q, want the upstream value", got)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if got := resp.Header.Get("X-Upstream-Hop"); got != "" {
+		t.Errorf("hop-by-hop header X-Upstream-Hop = %q was forwarded", got)
+	}
+}
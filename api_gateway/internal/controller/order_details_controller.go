@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/client"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/middleware"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/usecase"
+	"net/http"
+	"strconv"
+)
+
+type OrderDetailsController struct {
+	orderDetailsUseCase *usecase.OrderDetailsUseCase
+}
+
+func NewOrderDetailsController(orderDetailsUseCase *usecase.OrderDetailsUseCase) *OrderDetailsController {
+	return &OrderDetailsController{orderDetailsUseCase: orderDetailsUseCase}
+}
+
+func (c *OrderDetailsController) GetOrderDetails(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	details, err := c.orderDetailsUseCase.GetOrderDetails(ctx.Request.Context(), uint(id), middleware.CallerFrom(ctx), ctx.GetHeader("Authorization"))
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) || errors.Is(err, usecase.ErrNotOwner) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, details)
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	"github.com/rrxshxd/assignment1_advProg2/proto/user"
+	"net/http"
+	"net/textproto"
+)
+
+// forwardedMetadata lists gateway-controlled headers that are passed to the
+// user service as gRPC metadata in addition to grpc-gateway's defaults.
+var forwardedMetadata = map[string]string{
+	requestid.Header: requestid.MetadataKey,
+	"X-User-Id":      "x-user-id",
+	"X-Api-Key-Id":   "x-api-key-id",
+}
+
+// NewUserTranscoder maps the google.api.http annotations of UserService onto
+// REST routes backed by the existing gRPC client, and serves the generated
+// OpenAPI document alongside them.
+func NewUserTranscoder(ctx context.Context, client user.UserServiceClient) (http.Handler, error) {
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(matchUserHeader))
+	if err := user.RegisterUserServiceHandlerClient(ctx, mux, client); err != nil {
+		return nil, err
+	}
+
+	err := mux.HandlePath(http.MethodGet, "/v1/openapi.json", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(user.OpenAPI)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mux, nil
+}
+
+func matchUserHeader(key string) (string, bool) {
+	if name, ok := forwardedMetadata[textproto.CanonicalMIMEHeaderKey(key)]; ok {
+		return name, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
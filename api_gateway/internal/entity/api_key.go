@@ -0,0 +1,7 @@
+package entity
+
+type APIKeyIdentity struct {
+	KeyID  uint
+	UserID uint
+	Scopes []string
+}
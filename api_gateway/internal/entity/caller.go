@@ -0,0 +1,21 @@
+package entity
+
+// ScopeAll is the admin scope; it grants every other scope.
+const ScopeAll = "*"
+
+// Caller is the user behind an authenticated request, whichever credential
+// it presented.
+type Caller struct {
+	UserID uint
+	Scopes []string
+}
+
+// IsAdmin reports whether the caller holds the admin scope.
+func (c *Caller) IsAdmin() bool {
+	for _, s := range c.Scopes {
+		if s == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
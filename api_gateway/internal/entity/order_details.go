@@ -0,0 +1,65 @@
+package entity
+
+import "time"
+
+type OrderItem struct {
+	ProductID uint    `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+type Order struct {
+	ID        uint        `json:"id"`
+	UserID    uint        `json:"user_id"`
+	Items     []OrderItem `json:"items"`
+	Total     float64     `json:"total"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type Product struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+}
+
+type Address struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+type UserProfile struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	Addresses []Address `json:"-"`
+	Default   *Address  `json:"-"`
+}
+
+type OrderItemDetails struct {
+	ProductID   uint    `json:"product_id"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+	Name        string  `json:"name,omitempty"`
+	Description string  `json:"description,omitempty"`
+}
+
+type OrderDetails struct {
+	ID              uint               `json:"id"`
+	UserID          uint               `json:"user_id"`
+	Status          string             `json:"status"`
+	Total           float64            `json:"total"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	Items           []OrderItemDetails `json:"items"`
+	User            *UserProfile       `json:"user,omitempty"`
+	ShippingAddress *Address           `json:"shipping_address,omitempty"`
+	Warnings        []string           `json:"warnings,omitempty"`
+}
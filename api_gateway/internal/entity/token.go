@@ -0,0 +1,18 @@
+package entity
+
+// TokenIdentity is the caller named by a verified access token.
+type TokenIdentity struct {
+	UserID uint
+	Scopes []string
+}
+
+// HasScope reports whether the token grants scope, either directly or via the
+// wildcard scope "*".
+func (t *TokenIdentity) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == "*" || s == scope {
+			return true
+		}
+	}
+	return false
+}
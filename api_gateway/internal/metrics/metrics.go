@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	sharedmetrics "github.com/rrxshxd/assignment1_advProg2/observability/metrics"
+	"net/http"
+)
+
+// registry holds this service's metrics instead of the global default, so
+// several services can share a process without their metric names clashing.
+var registry = sharedmetrics.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var httpMetrics = sharedmetrics.NewHTTP(registry)
+
+// HTTPMiddleware records rate, errors and duration per route.
+func HTTPMiddleware() gin.HandlerFunc {
+	return httpMetrics.Middleware()
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	upstreamDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gateway_upstream_request_duration_seconds",
+		Help:    "Latency of calls from the gateway to upstream services, by upstream, method and result code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"upstream", "method", "code"})
+
+	upstreamErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_upstream_errors_total",
+		Help: "Upstream calls that failed in transport or returned a server error.",
+	}, []string{"upstream", "method"})
+)
+
+type upstreamTransport struct {
+	upstream string
+	next     http.RoundTripper
+}
+
+// InstrumentTransport records latency and failures of every request sent
+// through next. A nil next uses http.DefaultTransport.
+func InstrumentTransport(upstream string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &upstreamTransport{upstream: upstream, next: next}
+}
+
+func (t *upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	upstreamDuration.WithLabelValues(t.upstream, req.Method, code).Observe(time.Since(start).Seconds())
+	if err != nil || resp.StatusCode >= 500 {
+		upstreamErrors.WithLabelValues(t.upstream, req.Method).Inc()
+	}
+
+	return resp, err
+}
+
+// UnaryClientInterceptor is the gRPC counterpart of InstrumentTransport. The
+// method label is the full RPC name and the code is the gRPC status code.
+func UnaryClientInterceptor(upstream string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		code := status.Code(err)
+		upstreamDuration.WithLabelValues(upstream, method, code.String()).Observe(time.Since(start).Seconds())
+		if isServerError(code) {
+			upstreamErrors.WithLabelValues(upstream, method).Inc()
+		}
+
+		return err
+	}
+}
+
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/client"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	UserIDHeader = "X-User-ID"
+	ScopesHeader = "X-User-Scopes"
+
+	ContextAPIKey = "api_key"
+	ContextToken  = "token"
+	ContextCaller = "caller"
+)
+
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key, requiredScope string) (*entity.APIKeyIdentity, error)
+}
+
+type TokenVerifier interface {
+	VerifyToken(token string) (*entity.TokenIdentity, error)
+}
+
+// Authenticate requires an X-API-Key header or a bearer token granting the
+// "<resource>:read" or "<resource>:write" scope, depending on the method.
+// With publicReads set, reads without credentials pass through anonymously.
+func Authenticate(keys APIKeyValidator, tokens TokenVerifier, resource string, publicReads bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(APIKeyHeader)
+		authorization := ctx.GetHeader("Authorization")
+		scope := requiredScope(resource, ctx.Request.Method)
+
+		switch {
+		case key != "" && authorization != "":
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "use either an API key or a bearer token, not both"})
+		case key != "":
+			authenticateAPIKey(ctx, keys, key, scope)
+		case authorization != "":
+			authenticateToken(ctx, tokens, authorization, scope)
+		case publicReads && isRead(ctx.Request.Method):
+			ctx.Next()
+		default:
+			ctx.Header("WWW-Authenticate", `Bearer realm="api"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "an API key or a bearer token is required"})
+		}
+	}
+}
+
+func authenticateAPIKey(ctx *gin.Context, validator APIKeyValidator, key, scope string) {
+	identity, err := validator.ValidateAPIKey(ctx.Request.Context(), key, scope)
+	if err != nil {
+		if errors.Is(err, client.ErrInvalidAPIKey) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, client.ErrAPIKeyForbidden) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The raw key never leaves the gateway; upstreams only see the owner.
+	ctx.Request.Header.Del(APIKeyHeader)
+	ctx.Request.Header.Set("X-API-Key-ID", strconv.FormatUint(uint64(identity.KeyID), 10))
+	ctx.Set(ContextAPIKey, identity)
+	setCaller(ctx, &entity.Caller{UserID: identity.UserID, Scopes: identity.Scopes})
+
+	ctx.Next()
+}
+
+func authenticateToken(ctx *gin.Context, verifier TokenVerifier, authorization, scope string) {
+	token, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found || token == "" {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization must use the Bearer scheme"})
+		return
+	}
+
+	identity, err := verifier.VerifyToken(token)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		return
+	}
+	if !identity.HasScope(scope) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token lacks required scope " + strconv.Quote(scope)})
+		return
+	}
+
+	// The token itself is still forwarded: the user service checks it again
+	// when the order details aggregation loads the profile.
+	ctx.Set(ContextToken, identity)
+	setCaller(ctx, &entity.Caller{UserID: identity.UserID, Scopes: identity.Scopes})
+
+	ctx.Next()
+}
+
+// setCaller names the caller to handlers and, through the identity headers,
+// to the upstream services, which trust them.
+func setCaller(ctx *gin.Context, caller *entity.Caller) {
+	ctx.Request.Header.Set(UserIDHeader, strconv.FormatUint(uint64(caller.UserID), 10))
+	ctx.Request.Header.Set(ScopesHeader, strings.Join(caller.Scopes, ","))
+	ctx.Set(ContextCaller, caller)
+}
+
+// CallerFrom returns the caller set by Authenticate, or nil for anonymous
+// requests.
+func CallerFrom(ctx *gin.Context) *entity.Caller {
+	caller, _ := ctx.Get(ContextCaller)
+	c, _ := caller.(*entity.Caller)
+	return c
+}
+
+func requiredScope(resource, method string) string {
+	if isRead(method) {
+		return resource + ":read"
+	}
+	return resource + ":write"
+}
+
+func isRead(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
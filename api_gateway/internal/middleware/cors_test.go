@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	allowList := CORSConfig{
+		AllowedOrigins:   []string{"https://shop.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	wildcard := CORSConfig{
+		AllowedOrigins: []string{"https://shop.example.com", "*"},
+		AllowedMethods: []string{"GET"},
+	}
+
+	tests := []struct {
+		name       string
+		cfg        CORSConfig
+		method     string
+		origin     string
+		preflight  string
+		wantCode   int
+		wantHeader map[string]string
+	}{
+		{
+			name:     "disabled",
+			cfg:      CORSConfig{},
+			method:   http.MethodGet,
+			origin:   "https://shop.example.com",
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:     "allowed origin",
+			cfg:      allowList,
+			method:   http.MethodGet,
+			origin:   "https://shop.example.com",
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "https://shop.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "Etag",
+			},
+		},
+		{
+			name:     "disallowed origin",
+			cfg:      allowList,
+			method:   http.MethodGet,
+			origin:   "https://evil.example.com",
+			wantCode: http.StatusForbidden,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:      "preflight",
+			cfg:       allowList,
+			method:    http.MethodOptions,
+			origin:    "https://shop.example.com",
+			preflight: http.MethodPost,
+			wantCode:  http.StatusNoContent,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "https://shop.example.com",
+				"Access-Control-Allow-Methods":     "GET,POST",
+				"Access-Control-Allow-Headers":     "Authorization,Content-Type",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:      "preflight from disallowed origin",
+			cfg:       allowList,
+			method:    http.MethodOptions,
+			origin:    "https://evil.example.com",
+			preflight: http.MethodPost,
+			wantCode:  http.StatusForbidden,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:     "wildcard",
+			cfg:      wildcard,
+			method:   http.MethodGet,
+			origin:   "https://anyone.example.org",
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:     "same origin",
+			cfg:      allowList,
+			method:   http.MethodGet,
+			origin:   "http://gateway.local",
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CORS(tt.cfg))
+			router.GET("/products", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, "http://gateway.local/products", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.preflight != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.preflight)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			for name, want := range tt.wantHeader {
+				if got := rec.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/proxy"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	"regexp"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// StripIdentityHeaders drops headers that only the gateway may assert, so
+// clients cannot impersonate a user towards the upstream services.
+func StripIdentityHeaders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		for _, name := range proxy.IdentityHeaders {
+			ctx.Request.Header.Del(name)
+		}
+		ctx.Next()
+	}
+}
+
+// RequestID keeps a well-formed X-Request-ID from a trusted proxy and
+// generates a fresh one otherwise, exposing it to handlers, upstreams and
+// the client.
+func RequestID(forwarding *proxy.Forwarding) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestid.Header)
+		if !forwarding.IsTrustedPeer(ctx.Request) || !validRequestID.MatchString(id) {
+			id = requestid.New()
+		}
+
+		ctx.Request.Header.Set(requestid.Header, id)
+		ctx.Request = ctx.Request.WithContext(requestid.NewContext(ctx.Request.Context(), id))
+		ctx.Header(requestid.Header, id)
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/proxy"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type tokenVerifier map[string]*entity.TokenIdentity
+
+func (v tokenVerifier) VerifyToken(token string) (*entity.TokenIdentity, error) {
+	identity, ok := v[token]
+	if !ok {
+		return nil, errors.New("unknown token")
+	}
+	return identity, nil
+}
+
+func TestStripIdentityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	forwarding, err := proxy.NewForwarding(nil)
+	if err != nil {
+		t.Fatalf("NewForwarding: %v", err)
+	}
+	tokens := tokenVerifier{"t0k": {UserID: 7, Scopes: []string{"orders:read"}}}
+
+	spoofed := map[string]string{
+		"X-User-ID":            "1",
+		"X-User-Scopes":        "*",
+		"X-API-Key-ID":         "42",
+		"X-Authenticated-User": "admin",
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          map[string]string
+	}{
+		{
+			name: "anonymous",
+			want: map[string]string{"X-User-ID": "", "X-User-Scopes": "", "X-API-Key-ID": "", "X-Authenticated-User": ""},
+		},
+		{
+			name:          "authenticated",
+			authorization: "Bearer t0k",
+			want:          map[string]string{"X-User-ID": "7", "X-User-Scopes": "orders:read", "X-API-Key-ID": "", "X-Authenticated-User": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upstream http.Header
+			router := gin.New()
+			router.Use(StripIdentityHeaders())
+			router.GET("/orders", Authenticate(nil, tokens, "orders", true), func(ctx *gin.Context) {
+				upstream = forwarding.OutboundHeader(ctx.Request)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			for name, value := range spoofed {
+				req.Header.Set(name, value)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if upstream == nil {
+				t.Fatal("request never reached the handler")
+			}
+			for name, want := range tt.want {
+				if got := upstream.Get(name); got != want {
+					t.Errorf("upstream %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
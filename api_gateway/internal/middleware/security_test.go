@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	always := map[string]string{
+		"X-Content-Type-Options":     "nosniff",
+		"X-Frame-Options":            "DENY",
+		"Referrer-Policy":            "no-referrer",
+		"Cross-Origin-Opener-Policy": "same-origin",
+	}
+
+	tests := []struct {
+		name       string
+		cfg        SecurityHeadersConfig
+		wantHeader map[string]string
+	}{
+		{
+			name: "defaults",
+			cfg: SecurityHeadersConfig{
+				HSTSMaxAge:            365 * 24 * time.Hour,
+				ContentSecurityPolicy: "default-src 'none'",
+			},
+			wantHeader: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"Content-Security-Policy":   "default-src 'none'",
+			},
+		},
+		{
+			name: "HSTS and CSP disabled",
+			cfg:  SecurityHeadersConfig{},
+			wantHeader: map[string]string{
+				"Strict-Transport-Security": "",
+				"Content-Security-Policy":   "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SecurityHeaders(tt.cfg))
+			router.GET("/products", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+			for name, want := range always {
+				if got := rec.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			for name, want := range tt.wantHeader {
+				if got := rec.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("error responses", func(t *testing.T) {
+		router := gin.New()
+		router.Use(SecurityHeaders(SecurityHeadersConfig{}))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+		if rec.Code != http.StatusNotFound || rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("404 response = %d with headers %v, want the security headers", rec.Code, rec.Header())
+		}
+	})
+}
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		limit    int64
+		body     string
+		chunked  bool
+		wantCode int
+	}{
+		{name: "within limit", limit: 8, body: "12345678", wantCode: http.StatusOK},
+		{name: "declared too large", limit: 8, body: "123456789", wantCode: http.StatusRequestEntityTooLarge},
+		{name: "chunked within limit", limit: 8, body: "1234", chunked: true, wantCode: http.StatusOK},
+		{name: "chunked too large", limit: 8, body: "123456789", chunked: true, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "no limit", limit: 0, body: strings.Repeat("x", 1<<16), wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			router := gin.New()
+			router.POST("/orders", BodyLimit(tt.limit), func(ctx *gin.Context) {
+				reached = true
+				if _, err := io.ReadAll(ctx.Request.Body); err != nil {
+					var maxBytesErr *http.MaxBytesError
+					if errors.As(err, &maxBytesErr) {
+						ctx.Status(http.StatusRequestEntityTooLarge)
+						return
+					}
+					ctx.Status(http.StatusInternalServerError)
+					return
+				}
+				ctx.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			// Declared oversized bodies are rejected before the handler runs.
+			if wantReached := tt.chunked || tt.wantCode == http.StatusOK; reached != wantReached {
+				t.Errorf("handler reached = %v, want %v", reached, wantReached)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Package openapi describes the gateway's public HTTP API as an OpenAPI 3
+// document generated from the services' dto types.
+package openapi
+
+import (
+	"context"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	inventorydto "github.com/rrxshxd/assignment1_advProg2/inventory_service/dto"
+	sharedopenapi "github.com/rrxshxd/assignment1_advProg2/openapi"
+	orderdto "github.com/rrxshxd/assignment1_advProg2/order_service/dto"
+	"net/http"
+)
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// Spec returns the validated OpenAPI document of the gateway's REST routes.
+// The /v1 user API is transcoded from the user service's gRPC definitions
+// and is not part of it.
+func Spec(ctx context.Context) (*openapi3.T, error) {
+	schemas, err := sharedopenapi.GenerateSchemas(map[string]any{
+		"Product":                  inventorydto.Product{},
+		"CreateProductRequest":     inventorydto.CreateProductRequest{},
+		"UpdateProductRequest":     inventorydto.UpdateProductRequest{},
+		"ReplaceProductRequest":    inventorydto.ReplaceProductRequest{},
+		"ProductList":              inventorydto.ProductList{},
+		"Order":                    orderdto.Order{},
+		"CreateOrderRequest":       orderdto.CreateOrderRequest{},
+		"UpdateOrderStatusRequest": orderdto.UpdateOrderStatusRequest{},
+		"OrderDetails":             entity.OrderDetails{},
+		"Error":                    errorBody{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	listParameters, err := sharedopenapi.QueryParameters(inventorydto.ProductQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	productID := sharedopenapi.IDParameter("Product ID")
+	orderID := sharedopenapi.IDParameter("Order ID")
+	invalid := sharedopenapi.JSONResponse("Invalid request", "Error")
+	productNotFound := sharedopenapi.JSONResponse("Product not found", "Error")
+	orderNotFound := sharedopenapi.JSONResponse("Order not found", "Error")
+	authenticated := openapi3.SecurityRequirements{
+		openapi3.NewSecurityRequirement().Authenticate("apiKey"),
+		openapi3.NewSecurityRequirement().Authenticate("bearer"),
+	}
+	anonymous := append(openapi3.SecurityRequirements{openapi3.NewSecurityRequirement()}, authenticated...)
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "API gateway",
+			Description: "Authenticate with an API key in X-API-Key or a user's bearer token. Only product reads are served anonymously.",
+			Version:     "1.0.0",
+		},
+		Components: &openapi3.Components{
+			Schemas: schemas,
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"apiKey": &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().WithType("apiKey").WithIn("header").WithName("X-API-Key")},
+				"bearer": &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme()},
+			},
+		},
+		Security: authenticated,
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/inventory/products", &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					OperationID: "listProducts",
+					Tags:        []string{"inventory"},
+					Summary:     "List products matching the filters, one page at a time",
+					Security:    &anonymous,
+					Parameters:  listParameters,
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusOK:          sharedopenapi.JSONResponse("A page of products", "ProductList"),
+						http.StatusNotModified: sharedopenapi.EmptyResponse("The page matches the client's ETag"),
+						http.StatusBadRequest:  invalid,
+					}),
+				},
+			}),
+			openapi3.WithPath("/inventory/products/create", &openapi3.PathItem{
+				Post: &openapi3.Operation{
+					OperationID: "createProduct",
+					Tags:        []string{"inventory"},
+					Summary:     "Create a product",
+					RequestBody: sharedopenapi.JSONBody("CreateProductRequest"),
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusCreated:    sharedopenapi.JSONResponse("The created product", "Product"),
+						http.StatusBadRequest: invalid,
+					}),
+				},
+			}),
+			openapi3.WithPath("/inventory/products/{id}", &openapi3.PathItem{
+				Parameters: openapi3.Parameters{productID},
+				Get: &openapi3.Operation{
+					OperationID: "getProduct",
+					Tags:        []string{"inventory"},
+					Summary:     "Get a product",
+					Security:    &anonymous,
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusOK:          sharedopenapi.JSONResponse("The product", "Product"),
+						http.StatusNotModified: sharedopenapi.EmptyResponse("The product matches the client's ETag"),
+						http.StatusBadRequest:  invalid,
+						http.StatusNotFound:    productNotFound,
+					}),
+				},
+				Patch: &openapi3.Operation{
+					OperationID: "updateProduct",
+					Tags:        []string{"inventory"},
+					Summary:     "Change the fields present in the body, leaving the others as they are",
+					RequestBody: sharedopenapi.JSONBody("UpdateProductRequest"),
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusOK:         sharedopenapi.JSONResponse("The updated product", "Product"),
+						http.StatusBadRequest: invalid,
+						http.StatusNotFound:   productNotFound,
+					}),
+				},
+				Put: &openapi3.Operation{
+					OperationID: "replaceProduct",
+					Tags:        []string{"inventory"},
+					Summary:     "Replace every field of a product",
+					RequestBody: sharedopenapi.JSONBody("ReplaceProductRequest"),
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusOK:         sharedopenapi.JSONResponse("The replaced product", "Product"),
+						http.StatusBadRequest: invalid,
+						http.StatusNotFound:   productNotFound,
+					}),
+				},
+				Delete: &openapi3.Operation{
+					OperationID: "deleteProduct",
+					Tags:        []string{"inventory"},
+					Summary:     "Delete a product",
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusNoContent:  sharedopenapi.EmptyResponse("The product was deleted"),
+						http.StatusBadRequest: invalid,
+						http.StatusNotFound:   productNotFound,
+					}),
+				},
+			}),
+			openapi3.WithPath("/orders/", &openapi3.PathItem{
+				Post: &openapi3.Operation{
+					OperationID: "createOrder",
+					Tags:        []string{"orders"},
+					Summary:     "Create an order owned by the caller",
+					RequestBody: sharedopenapi.JSONBody("CreateOrderRequest"),
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusCreated:    sharedopenapi.JSONResponse("The created order", "Order"),
+						http.StatusBadRequest: invalid,
+					}),
+				},
+			}),
+			openapi3.WithPath("/orders/{id}", &openapi3.PathItem{
+				Parameters: openapi3.Parameters{orderID},
+				Get: &openapi3.Operation{
+					OperationID: "getOrder",
+					Tags:        []string{"orders"},
+					Summary:     "Get one of the caller's orders; admins may get any order",
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusOK:         sharedopenapi.JSONResponse("The order", "Order"),
+						http.StatusBadRequest: invalid,
+						http.StatusNotFound:   orderNotFound,
+					}),
+				},
+				Patch: &openapi3.Operation{
+					OperationID: "updateOrderStatus",
+					Tags:        []string{"orders"},
+					Summary:     "Change the status of one of the caller's orders; only admins may set a status other than cancelled",
+					RequestBody: sharedopenapi.JSONBody("UpdateOrderStatusRequest"),
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusNoContent:  sharedopenapi.EmptyResponse("The status was changed"),
+						http.StatusBadRequest: invalid,
+						http.StatusForbidden:  sharedopenapi.JSONResponse("The credentials lack orders:write, or a status other than cancelled was set without the admin scope", "Error"),
+						http.StatusNotFound:   orderNotFound,
+					}),
+				},
+			}),
+			openapi3.WithPath("/api/orders/{id}/details", &openapi3.PathItem{
+				Parameters: openapi3.Parameters{orderID},
+				Get: &openapi3.Operation{
+					OperationID: "getOrderDetails",
+					Tags:        []string{"orders"},
+					Summary:     "Get an order with its products and, given a bearer token, its owner's profile",
+					Responses: proxied(map[int]*openapi3.ResponseRef{
+						http.StatusOK:         sharedopenapi.JSONResponse("The order details; parts that could not be fetched are listed in warnings", "OrderDetails"),
+						http.StatusBadRequest: invalid,
+						http.StatusNotFound:   orderNotFound,
+					}),
+				},
+			}),
+		),
+	}
+
+	if err := sharedopenapi.Resolve(ctx, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// proxied adds the responses the gateway itself can give to an operation's
+// own: authentication failures, oversized bodies and unreachable upstreams.
+func proxied(statuses map[int]*openapi3.ResponseRef) *openapi3.Responses {
+	gatewayStatuses := map[int]string{
+		http.StatusUnauthorized:          "Missing or invalid API key or bearer token",
+		http.StatusForbidden:             "The API key or token lacks the required scope",
+		http.StatusRequestEntityTooLarge: "Request body too large",
+		http.StatusBadGateway:            "Upstream service unavailable",
+	}
+	for status, description := range gatewayStatuses {
+		if _, ok := statuses[status]; !ok {
+			statuses[status] = sharedopenapi.JSONResponse(description, "Error")
+		}
+	}
+	return sharedopenapi.Responses(statuses)
+}
@@ -0,0 +1,181 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Hop-by-hop headers as defined by RFC 9110 section 7.6.1; they describe a
+// single connection and must not be forwarded.
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// IdentityHeaders are set by the gateway after authentication and are
+// therefore never accepted from clients.
+var IdentityHeaders = []string{
+	"X-User-ID",
+	"X-User-Scopes",
+	"X-API-Key-ID",
+	"X-Authenticated-User",
+}
+
+var forwardingHeaders = []string{
+	"Forwarded",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Real-IP",
+}
+
+func RemoveHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
+
+// CopyResponseHeader copies upstream response headers without hop-by-hop
+// fields; headers the gateway has already set take precedence.
+func CopyResponseHeader(dst, src http.Header) {
+	cleaned := src.Clone()
+	RemoveHopHeaders(cleaned)
+	for key, values := range cleaned {
+		if _, exists := dst[key]; exists {
+			continue
+		}
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
+type Forwarding struct {
+	trusted []*net.IPNet
+}
+
+// NewForwarding accepts trusted proxies as CIDRs or bare IP addresses.
+func NewForwarding(trustedProxies []string) (*Forwarding, error) {
+	f := &Forwarding{}
+	for _, entry := range trustedProxies {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			entry = fmt.Sprintf("%s/%d", ip, bits)
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		f.trusted = append(f.trusted, network)
+	}
+	return f, nil
+}
+
+func (f *Forwarding) IsTrustedPeer(req *http.Request) bool {
+	ip := remoteIP(req)
+	if ip == nil {
+		return false
+	}
+	for _, network := range f.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboundHeader builds the header set for an upstream request: hop-by-hop
+// headers are removed, and forwarding headers are only extended from the
+// client's values when the immediate peer is a trusted proxy.
+func (f *Forwarding) OutboundHeader(req *http.Request) http.Header {
+	header := req.Header.Clone()
+	RemoveHopHeaders(header)
+	header.Del("Host")
+
+	trusted := f.IsTrustedPeer(req)
+	priorFor := header.Values("X-Forwarded-For")
+	priorForwarded := header.Values("Forwarded")
+	proto := header.Get("X-Forwarded-Proto")
+	host := header.Get("X-Forwarded-Host")
+
+	for _, name := range forwardingHeaders {
+		header.Del(name)
+	}
+
+	if !trusted {
+		priorFor, priorForwarded = nil, nil
+		proto, host = "", ""
+	}
+	if proto == "" {
+		proto = "http"
+		if req.TLS != nil {
+			proto = "https"
+		}
+	}
+	if host == "" {
+		host = req.Host
+	}
+
+	peer := ""
+	if ip := remoteIP(req); ip != nil {
+		peer = ip.String()
+	}
+
+	forwardedFor := append([]string{}, priorFor...)
+	if peer != "" {
+		forwardedFor = append(forwardedFor, peer)
+	}
+	if len(forwardedFor) > 0 {
+		header.Set("X-Forwarded-For", strings.Join(forwardedFor, ", "))
+	}
+	header.Set("X-Forwarded-Proto", proto)
+	header.Set("X-Forwarded-Host", host)
+
+	element := fmt.Sprintf("for=%s;host=%q;proto=%s", forwardedNode(peer), host, proto)
+	header.Set("Forwarded", strings.Join(append(priorForwarded, element), ", "))
+
+	return header
+}
+
+func remoteIP(req *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(req.RemoteAddr))
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// forwardedNode formats a node for the Forwarded header, which requires IPv6
+// addresses to be bracketed and quoted.
+func forwardedNode(ip string) string {
+	if ip == "" {
+		return "unknown"
+	}
+	if strings.Contains(ip, ":") {
+		return fmt.Sprintf("%q", "["+ip+"]")
+	}
+	return ip
+}
@@ -0,0 +1,221 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRemoveHopHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   http.Header
+	}{
+		{
+			name: "standard hop-by-hop headers",
+			header: http.Header{
+				"Connection":          {"keep-alive"},
+				"Keep-Alive":          {"timeout=5"},
+				"Proxy-Authorization": {"Basic Zm9v"},
+				"Te":                  {"trailers"},
+				"Transfer-Encoding":   {"chunked"},
+				"Upgrade":             {"websocket"},
+				"Content-Type":        {"application/json"},
+			},
+			want: http.Header{"Content-Type": {"application/json"}},
+		},
+		{
+			name: "headers named in Connection",
+			header: http.Header{
+				"Connection":   {"X-Hop, x-other-hop", "X-Third"},
+				"X-Hop":        {"1"},
+				"X-Other-Hop":  {"2"},
+				"X-Third":      {"3"},
+				"X-End-To-End": {"kept"},
+			},
+			want: http.Header{"X-End-To-End": {"kept"}},
+		},
+		{
+			name: "empty Connection tokens",
+			header: http.Header{
+				"Connection": {" , ,"},
+				"Accept":     {"*/*"},
+			},
+			want: http.Header{"Accept": {"*/*"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RemoveHopHeaders(tt.header)
+			if !reflect.DeepEqual(tt.header, tt.want) {
+				t.Errorf("header = %v, want %v", tt.header, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyResponseHeader(t *testing.T) {
+	dst := http.Header{"X-Request-Id": {"gateway"}}
+	src := http.Header{
+		"Connection":     {"X-Upstream-Hop"},
+		"X-Upstream-Hop": {"1"},
+		"X-Request-Id":   {"upstream"},
+		"Location":       {"/orders/7"},
+		"Set-Cookie":     {"a=1", "b=2"},
+	}
+
+	CopyResponseHeader(dst, src)
+
+	want := http.Header{
+		"X-Request-Id": {"gateway"},
+		"Location":     {"/orders/7"},
+		"Set-Cookie":   {"a=1", "b=2"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("header = %v, want %v", dst, want)
+	}
+	if src.Get("Connection") == "" {
+		t.Error("CopyResponseHeader modified the upstream header")
+	}
+}
+
+func TestNewForwarding(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "IPv4 address", proxies: []string{"10.0.0.1"}},
+		{name: "IPv6 address", proxies: []string{"::1"}},
+		{name: "CIDRs", proxies: []string{"10.0.0.0/8", "fd00::/8"}},
+		{name: "hostname", proxies: []string{"proxy.internal"}, wantErr: true},
+		{name: "bad CIDR", proxies: []string{"10.0.0.0/33"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewForwarding(tt.proxies)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewForwarding(%q) error = %v, want error: %v", tt.proxies, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutboundHeader(t *testing.T) {
+	forwarding, err := NewForwarding([]string{"10.0.0.0/8", "fd00::1"})
+	if err != nil {
+		t.Fatalf("NewForwarding: %v", err)
+	}
+
+	spoofed := http.Header{
+		"X-Forwarded-For":   {"203.0.113.9"},
+		"X-Forwarded-Proto": {"https"},
+		"X-Forwarded-Host":  {"shop.example.com"},
+		"Forwarded":         {"for=203.0.113.9;host=shop.example.com;proto=https"},
+		"X-Real-Ip":         {"203.0.113.9"},
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		header     http.Header
+		want       map[string]string
+	}{
+		{
+			name:       "untrusted peer without forwarding headers",
+			remoteAddr: "198.51.100.4:5000",
+			want: map[string]string{
+				"X-Forwarded-For":   "198.51.100.4",
+				"X-Forwarded-Proto": "http",
+				"X-Forwarded-Host":  "gateway.local",
+				"Forwarded":         `for=198.51.100.4;host="gateway.local";proto=http`,
+			},
+		},
+		{
+			name:       "untrusted peer cannot spoof forwarding headers",
+			remoteAddr: "198.51.100.4:5000",
+			header:     spoofed,
+			want: map[string]string{
+				"X-Forwarded-For":   "198.51.100.4",
+				"X-Forwarded-Proto": "http",
+				"X-Forwarded-Host":  "gateway.local",
+				"Forwarded":         `for=198.51.100.4;host="gateway.local";proto=http`,
+				"X-Real-Ip":         "",
+			},
+		},
+		{
+			name:       "trusted peer extends forwarding headers",
+			remoteAddr: "10.1.2.3:5000",
+			header:     spoofed,
+			want: map[string]string{
+				"X-Forwarded-For":   "203.0.113.9, 10.1.2.3",
+				"X-Forwarded-Proto": "https",
+				"X-Forwarded-Host":  "shop.example.com",
+				"Forwarded":         `for=203.0.113.9;host=shop.example.com;proto=https, for=10.1.2.3;host="shop.example.com";proto=https`,
+				"X-Real-Ip":         "",
+			},
+		},
+		{
+			name:       "trusted IPv6 peer",
+			remoteAddr: "[fd00::1]:5000",
+			want: map[string]string{
+				"X-Forwarded-For": "fd00::1",
+				"Forwarded":       `for="[fd00::1]";host="gateway.local";proto=http`,
+			},
+		},
+		{
+			name:       "TLS connection",
+			remoteAddr: "198.51.100.4:5000",
+			tls:        true,
+			want: map[string]string{
+				"X-Forwarded-Proto": "https",
+				"Forwarded":         `for=198.51.100.4;host="gateway.local";proto=https`,
+			},
+		},
+		{
+			name:       "hop-by-hop and Host headers are dropped",
+			remoteAddr: "198.51.100.4:5000",
+			header: http.Header{
+				"Connection":    {"X-Hop"},
+				"X-Hop":         {"1"},
+				"Te":            {"trailers"},
+				"Host":          {"gateway.local"},
+				"Authorization": {"Bearer t0k"},
+			},
+			want: map[string]string{
+				"Connection":    "",
+				"X-Hop":         "",
+				"Te":            "",
+				"Host":          "",
+				"Authorization": "Bearer t0k",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://gateway.local/orders", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+
+			header := forwarding.OutboundHeader(req)
+			for name, want := range tt.want {
+				if got := header.Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if _, ok := req.Header["X-Forwarded-For"]; !ok && tt.header["X-Forwarded-For"] != nil {
+				t.Error("OutboundHeader modified the incoming request")
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	"sync"
+	"time"
+)
+
+// ErrNotOwner is returned for an order of another user when the caller is
+// not an admin.
+var ErrNotOwner = errors.New("order belongs to another user")
+
+type OrderFetcher interface {
+	GetOrder(ctx context.Context, id uint, caller *entity.Caller) (*entity.Order, error)
+}
+
+type ProductFetcher interface {
+	GetProduct(ctx context.Context, id uint) (*entity.Product, error)
+}
+
+type UserProfileFetcher interface {
+	GetUserProfile(ctx context.Context, userID uint, authorization string) (*entity.UserProfile, error)
+}
+
+type OrderDetailsUseCase struct {
+	orders   OrderFetcher
+	products ProductFetcher
+	users    UserProfileFetcher
+	timeout  time.Duration
+}
+
+func NewOrderDetailsUseCase(orders OrderFetcher, products ProductFetcher, users UserProfileFetcher, timeout time.Duration) *OrderDetailsUseCase {
+	return &OrderDetailsUseCase{
+		orders:   orders,
+		products: products,
+		users:    users,
+		timeout:  timeout,
+	}
+}
+
+// GetOrderDetails fails only when the order itself cannot be loaded or
+// belongs to someone other than caller; missing products or profile data are
+// reported as warnings on a partial response. authorization is forwarded to
+// the user service to load the profile.
+func (uc *OrderDetailsUseCase) GetOrderDetails(ctx context.Context, orderID uint, caller *entity.Caller, authorization string) (*entity.OrderDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	order, err := uc.orders.GetOrder(ctx, orderID, caller)
+	if err != nil {
+		return nil, err
+	}
+	// The order service checks ownership too; this keeps the fan-out below
+	// from ever loading another user's profile.
+	if !caller.IsAdmin() && order.UserID != caller.UserID {
+		return nil, fmt.Errorf("%w: order %d", ErrNotOwner, orderID)
+	}
+
+	details := &entity.OrderDetails{
+		ID:        order.ID,
+		UserID:    order.UserID,
+		Status:    order.Status,
+		Total:     order.Total,
+		CreatedAt: order.CreatedAt,
+		UpdatedAt: order.UpdatedAt,
+		Items:     make([]entity.OrderItemDetails, 0, len(order.Items)),
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		products = make(map[uint]*entity.Product)
+	)
+
+	addWarning := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		details.Warnings = append(details.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	seen := make(map[uint]bool)
+	for _, item := range order.Items {
+		if seen[item.ProductID] {
+			continue
+		}
+		seen[item.ProductID] = true
+
+		wg.Add(1)
+		go func(productID uint) {
+			defer wg.Done()
+			product, err := uc.products.GetProduct(ctx, productID)
+			if err != nil {
+				addWarning("product %d unavailable: %v", productID, err)
+				return
+			}
+			mu.Lock()
+			products[productID] = product
+			mu.Unlock()
+		}(item.ProductID)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		profile, err := uc.users.GetUserProfile(ctx, order.UserID, authorization)
+		if err != nil {
+			addWarning("user profile unavailable: %v", err)
+			return
+		}
+		mu.Lock()
+		details.User = profile
+		details.ShippingAddress = profile.Default
+		mu.Unlock()
+	}()
+
+	wg.Wait()
+
+	for _, item := range order.Items {
+		itemDetails := entity.OrderItemDetails{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+		}
+		if product, ok := products[item.ProductID]; ok {
+			itemDetails.Name = product.Name
+			itemDetails.Description = product.Description
+		}
+		details.Items = append(details.Items, itemDetails)
+	}
+
+	return details, nil
+}
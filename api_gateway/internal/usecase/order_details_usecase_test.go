@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/api_gateway/internal/entity"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeOrders struct {
+	order *entity.Order
+	err   error
+}
+
+func (f *fakeOrders) GetOrder(_ context.Context, id uint, _ *entity.Caller) (*entity.Order, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	order := *f.order
+	order.ID = id
+	return &order, nil
+}
+
+// fakeProducts serves products by ID, failing for IDs in errs and blocking
+// until the context ends for IDs in slow. It counts the calls per ID.
+type fakeProducts struct {
+	products map[uint]*entity.Product
+	errs     map[uint]error
+	slow     map[uint]bool
+
+	mu    sync.Mutex
+	calls map[uint]int
+}
+
+func (f *fakeProducts) GetProduct(ctx context.Context, id uint) (*entity.Product, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[uint]int)
+	}
+	f.calls[id]++
+	f.mu.Unlock()
+
+	if f.slow[id] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if err := f.errs[id]; err != nil {
+		return nil, err
+	}
+	return f.products[id], nil
+}
+
+func (f *fakeProducts) totalCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	total := 0
+	for _, n := range f.calls {
+		total += n
+	}
+	return total
+}
+
+type fakeUsers struct {
+	profile *entity.UserProfile
+	err     error
+
+	mu            sync.Mutex
+	calls         int
+	authorization string
+}
+
+func (f *fakeUsers) GetUserProfile(_ context.Context, _ uint, authorization string) (*entity.UserProfile, error) {
+	f.mu.Lock()
+	f.calls++
+	f.authorization = authorization
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.profile, nil
+}
+
+func testOrder() *entity.Order {
+	return &entity.Order{
+		UserID: 7,
+		Status: "pending",
+		Total:  45,
+		Items: []entity.OrderItem{
+			{ProductID: 1, Quantity: 1, Price: 25},
+			{ProductID: 2, Quantity: 2, Price: 10},
+		},
+	}
+}
+
+func testProducts() *fakeProducts {
+	return &fakeProducts{products: map[uint]*entity.Product{
+		1: {ID: 1, Name: "Keyboard", Description: "Mechanical"},
+		2: {ID: 2, Name: "Mouse"},
+	}}
+}
+
+func testProfile() *entity.UserProfile {
+	home := &entity.Address{Street: "1 Main St", City: "Springfield"}
+	return &entity.UserProfile{ID: 7, Username: "ada", Addresses: []entity.Address{*home}, Default: home}
+}
+
+var owner = &entity.Caller{UserID: 7, Scopes: []string{"orders:read"}}
+
+func TestGetOrderDetails(t *testing.T) {
+	products, users := testProducts(), &fakeUsers{profile: testProfile()}
+	uc := NewOrderDetailsUseCase(&fakeOrders{order: testOrder()}, products, users, time.Second)
+
+	details, err := uc.GetOrderDetails(context.Background(), 3, owner, "Bearer t0k")
+	if err != nil {
+		t.Fatalf("GetOrderDetails: %v", err)
+	}
+	if details.ID != 3 || details.UserID != 7 || details.Total != 45 || len(details.Warnings) != 0 {
+		t.Errorf("details = %+v, want order 3 of user 7 without warnings", details)
+	}
+	if len(details.Items) != 2 || details.Items[0].Name != "Keyboard" || details.Items[0].Description != "Mechanical" || details.Items[1].Name != "Mouse" {
+		t.Errorf("items = %+v, want both named in order", details.Items)
+	}
+	if details.User == nil || details.User.Username != "ada" || details.ShippingAddress == nil || details.ShippingAddress.City != "Springfield" {
+		t.Errorf("user = %+v, shipping address = %+v, want the profile and its default address", details.User, details.ShippingAddress)
+	}
+	if users.authorization != "Bearer t0k" {
+		t.Errorf("profile loaded with authorization %q, want the caller's", users.authorization)
+	}
+}
+
+func TestGetOrderDetailsDegrades(t *testing.T) {
+	tests := []struct {
+		name         string
+		products     *fakeProducts
+		users        *fakeUsers
+		wantWarnings []string
+		wantNames    []string
+		wantUser     bool
+	}{
+		{
+			name:         "product unavailable",
+			products:     &fakeProducts{products: testProducts().products, errs: map[uint]error{2: errors.New("boom")}},
+			users:        &fakeUsers{profile: testProfile()},
+			wantWarnings: []string{"product 2 unavailable: boom"},
+			wantNames:    []string{"Keyboard", ""},
+			wantUser:     true,
+		},
+		{
+			name:         "profile unavailable",
+			products:     testProducts(),
+			users:        &fakeUsers{err: errors.New("PermissionDenied")},
+			wantWarnings: []string{"user profile unavailable: PermissionDenied"},
+			wantNames:    []string{"Keyboard", "Mouse"},
+		},
+		{
+			name:         "everything unavailable",
+			products:     &fakeProducts{errs: map[uint]error{1: errors.New("down"), 2: errors.New("down")}},
+			users:        &fakeUsers{err: errors.New("down")},
+			wantWarnings: []string{"product 1 unavailable", "product 2 unavailable", "user profile unavailable"},
+			wantNames:    []string{"", ""},
+		},
+		{
+			name:         "product too slow",
+			products:     &fakeProducts{products: testProducts().products, slow: map[uint]bool{1: true}},
+			users:        &fakeUsers{profile: testProfile()},
+			wantWarnings: []string{"product 1 unavailable: context deadline exceeded"},
+			wantNames:    []string{"", "Mouse"},
+			wantUser:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewOrderDetailsUseCase(&fakeOrders{order: testOrder()}, tt.products, tt.users, 50*time.Millisecond)
+
+			details, err := uc.GetOrderDetails(context.Background(), 3, owner, "")
+			if err != nil {
+				t.Fatalf("GetOrderDetails: %v", err)
+			}
+			if len(details.Warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %q, want %d of them", details.Warnings, len(tt.wantWarnings))
+			}
+			warnings := strings.Join(details.Warnings, "\n")
+			for _, want := range tt.wantWarnings {
+				if !strings.Contains(warnings, want) {
+					t.Errorf("warnings = %q, want one containing %q", details.Warnings, want)
+				}
+			}
+			for i, want := range tt.wantNames {
+				if details.Items[i].Name != want || details.Items[i].Price == 0 {
+					t.Errorf("item %d = %+v, want name %q and the ordered price", i, details.Items[i], want)
+				}
+			}
+			if (details.User != nil) != tt.wantUser {
+				t.Errorf("user = %+v, want present: %v", details.User, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestGetOrderDetailsFetchesEachProductOnce(t *testing.T) {
+	order := testOrder()
+	order.Items = append(order.Items, entity.OrderItem{ProductID: 1, Quantity: 3, Price: 25})
+	products := testProducts()
+	uc := NewOrderDetailsUseCase(&fakeOrders{order: order}, products, &fakeUsers{profile: testProfile()}, time.Second)
+
+	details, err := uc.GetOrderDetails(context.Background(), 3, owner, "")
+	if err != nil {
+		t.Fatalf("GetOrderDetails: %v", err)
+	}
+	if products.calls[1] != 1 || products.calls[2] != 1 {
+		t.Errorf("GetProduct calls = %v, want one per product", products.calls)
+	}
+	if len(details.Items) != 3 || details.Items[2].Name != "Keyboard" {
+		t.Errorf("items = %+v, want the repeated product named as well", details.Items)
+	}
+}
+
+func TestGetOrderDetailsChecksOwner(t *testing.T) {
+	tests := []struct {
+		name    string
+		caller  *entity.Caller
+		wantErr error
+	}{
+		{name: "owner", caller: owner},
+		{name: "other user", caller: &entity.Caller{UserID: 8, Scopes: []string{"orders:read"}}, wantErr: ErrNotOwner},
+		{name: "admin", caller: &entity.Caller{UserID: 1, Scopes: []string{entity.ScopeAll}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, users := testProducts(), &fakeUsers{profile: testProfile()}
+			uc := NewOrderDetailsUseCase(&fakeOrders{order: testOrder()}, products, users, time.Second)
+
+			details, err := uc.GetOrderDetails(context.Background(), 3, tt.caller, "")
+			if tt.wantErr == nil {
+				if err != nil || details.UserID != 7 {
+					t.Errorf("GetOrderDetails = %+v, %v, want the order", details, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetOrderDetails error = %v, want %v", err, tt.wantErr)
+			}
+			if products.totalCalls() != 0 || users.calls != 0 {
+				t.Errorf("fetched %d products and %d profiles for another user's order, want none", products.totalCalls(), users.calls)
+			}
+		})
+	}
+}
+
+func TestGetOrderDetailsOrderError(t *testing.T) {
+	orderErr := errors.New("order service down")
+	products, users := testProducts(), &fakeUsers{profile: testProfile()}
+	uc := NewOrderDetailsUseCase(&fakeOrders{err: orderErr}, products, users, time.Second)
+
+	if _, err := uc.GetOrderDetails(context.Background(), 3, owner, ""); !errors.Is(err, orderErr) {
+		t.Errorf("GetOrderDetails error = %v, want the order error", err)
+	}
+	if products.totalCalls() != 0 || users.calls != 0 {
+		t.Error("GetOrderDetails fanned out without an order")
+	}
+}
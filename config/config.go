@@ -0,0 +1,237 @@
+// Package config loads service configuration into tagged structs.
+//
+// Every exported field needs a yaml tag naming its key in the config file.
+// The environment variable defaults to the upper-cased key (nested structs
+// join keys with "_") and can be overridden with an env tag; the flag name is
+// the key with "_" and "." replaced by "-". Supported tags:
+//
+//	default:"..."   value used when no source sets the field
+//	env:"NAME"      environment variable to read instead of the derived one
+//	validate:"..."  comma-separated rules: required, oneof=a b c, min=N
+//	usage:"..."     help text for the flag
+//
+// Sources apply in increasing order of precedence: defaults, the YAML file
+// named by -config or CONFIG_FILE, environment variables, then flags. Any
+// variable can be read from a file instead by setting NAME_FILE, which is how
+// container secrets are usually mounted.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const (
+	fileFlag = "config"
+	fileEnv  = "CONFIG_FILE"
+)
+
+// Validator is implemented by configs with rules that span several fields.
+// Validate only runs once every field-level rule has passed.
+type Validator interface {
+	Validate() error
+}
+
+type field struct {
+	key        string
+	env        string
+	flag       string
+	usage      string
+	rules      string
+	def        string
+	hasDefault bool
+	value      reflect.Value
+}
+
+// Load fills dst, which must be a pointer to a struct, from all sources and
+// validates the result. args are the command-line arguments without the
+// program name; -h and malformed flags exit the process as the flag package
+// does.
+func Load(dst any, args []string) error {
+	fields, err := defaults(dst)
+	if err != nil {
+		return err
+	}
+
+	flagValues, configFile := parseFlags(fields, args)
+	if configFile == "" {
+		configFile = os.Getenv(fileEnv)
+	}
+	if configFile != "" {
+		if err := loadFile(configFile, dst); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+
+	var errs []error
+	for _, f := range fields {
+		raw, source, ok, err := lookupEnv(f.env)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+		}
+	}
+	for _, f := range fields {
+		raw, ok := flagValues[f.flag]
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("flag -%s: %w", f.flag, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		for _, f := range fields {
+			if err := validate(f); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		if v, ok := dst.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Defaults fills dst, which must be a pointer to a struct, with the values
+// of its default tags only. Nothing is read from files, the environment or
+// flags, and nothing is validated, which suits configs built in code such as
+// those of in-process tests.
+func Defaults(dst any) error {
+	_, err := defaults(dst)
+	return err
+}
+
+func defaults(dst any) ([]field, error) {
+	root := reflect.ValueOf(dst)
+	if root.Kind() != reflect.Pointer || root.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: need a pointer to a struct, got %T", dst)
+	}
+
+	fields, err := collect(root.Elem(), "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		if !f.hasDefault {
+			continue
+		}
+		if err := setValue(f.value, f.def); err != nil {
+			return nil, fmt.Errorf("config: invalid default for %s: %w", f.key, err)
+		}
+	}
+	return fields, nil
+}
+
+func collect(v reflect.Value, keyPrefix, envPrefix string) ([]field, error) {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if !sf.IsExported() || key == "-" {
+			continue
+		}
+		if key == "" {
+			return nil, fmt.Errorf("config: field %s.%s has no yaml tag", t.Name(), sf.Name)
+		}
+
+		env := sf.Tag.Get("env")
+		if env == "" {
+			env = envPrefix + strings.ToUpper(key)
+		}
+
+		if sf.Type.Kind() == reflect.Struct {
+			nested, err := collect(v.Field(i), keyPrefix+key+".", env+"_")
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		if !supported(sf.Type) {
+			return nil, fmt.Errorf("config: field %s.%s has unsupported type %s", t.Name(), sf.Name, sf.Type)
+		}
+
+		def, hasDefault := sf.Tag.Lookup("default")
+		fields = append(fields, field{
+			key:        keyPrefix + key,
+			env:        env,
+			flag:       strings.NewReplacer("_", "-", ".", "-").Replace(keyPrefix + key),
+			usage:      sf.Tag.Get("usage"),
+			rules:      sf.Tag.Get("validate"),
+			def:        def,
+			hasDefault: hasDefault,
+			value:      v.Field(i),
+		})
+	}
+	return fields, nil
+}
+
+// lookupEnv reads name, or the file named by name_FILE. Setting both is an
+// error so a stale variable cannot silently shadow a mounted secret.
+func lookupEnv(name string) (value, source string, ok bool, err error) {
+	direct, hasDirect := os.LookupEnv(name)
+	path, hasFile := os.LookupEnv(name + "_FILE")
+
+	switch {
+	case hasDirect && hasFile:
+		return "", "", false, fmt.Errorf("only one of %s and %s_FILE may be set", name, name)
+	case hasFile:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), name + "_FILE", true, nil
+	case hasDirect:
+		return direct, name, true, nil
+	}
+	return "", "", false, nil
+}
+
+// parseFlags registers one flag per field and returns the raw values of the
+// flags that were given, keyed by flag name, plus the -config path.
+func parseFlags(fields []field, args []string) (map[string]string, string) {
+	set := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ExitOnError)
+	configFile := set.String(fileFlag, "", "YAML config file (env "+fileEnv+")")
+
+	values := make(map[string]string)
+	for _, f := range fields {
+		usage := f.usage
+		if usage != "" {
+			usage += " "
+		}
+		usage += "(env " + f.env + ")"
+
+		name := f.flag
+		set.Func(name, usage, func(raw string) error {
+			values[name] = raw
+			return nil
+		})
+	}
+
+	set.Parse(args)
+	return values, *configFile
+}
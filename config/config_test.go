@@ -0,0 +1,339 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type limits struct {
+	Burst int `yaml:"burst" default:"10"`
+}
+
+type server struct {
+	Host   string `yaml:"host" default:"localhost"`
+	Token  string `yaml:"token" env:"CONFIGTEST_TOKEN"`
+	Limits limits `yaml:"limits"`
+}
+
+type testConfig struct {
+	Greeting string        `yaml:"greeting" default:"default"`
+	Timeout  time.Duration `yaml:"timeout" default:"1s"`
+	Origins  []string      `yaml:"origins"`
+	Server   server        `yaml:"server"`
+}
+
+var testEnv = []string{
+	"CONFIG_FILE",
+	"GREETING", "GREETING_FILE",
+	"TIMEOUT", "ORIGINS",
+	"SERVER_HOST", "SERVER_LIMITS_BURST",
+	"CONFIGTEST_TOKEN", "SERVER_TOKEN",
+}
+
+// clearEnv unsets names for the duration of the test, so variables set
+// outside the test cannot leak into it.
+func clearEnv(t *testing.T, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  string
+		flag string
+		want string
+	}{
+		{name: "default", want: "default"},
+		{name: "file", file: "file", want: "file"},
+		{name: "env", env: "env", want: "env"},
+		{name: "flag", flag: "flag", want: "flag"},
+		{name: "env over file", file: "file", env: "env", want: "env"},
+		{name: "flag over env", env: "env", flag: "flag", want: "flag"},
+		{name: "flag over file", file: "file", flag: "flag", want: "flag"},
+		{name: "all", file: "file", env: "env", flag: "flag", want: "flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t, testEnv...)
+			var args []string
+			if tt.file != "" {
+				args = append(args, "-config", writeFile(t, "config.yaml", "greeting: "+tt.file+"\n"))
+			}
+			if tt.env != "" {
+				t.Setenv("GREETING", tt.env)
+			}
+			if tt.flag != "" {
+				args = append(args, "-greeting", tt.flag)
+			}
+
+			var cfg testConfig
+			if err := Load(&cfg, args); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Greeting != tt.want {
+				t.Errorf("Greeting = %q, want %q", cfg.Greeting, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	clearEnv(t, testEnv...)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "server:\n  host: db.internal\n"))
+
+	var cfg testConfig
+	if err := Load(&cfg, nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Host != "db.internal" {
+		t.Errorf("Server.Host = %q, want the value from CONFIG_FILE", cfg.Server.Host)
+	}
+
+	flagged := writeFile(t, "flagged.yaml", "server:\n  host: flagged\n")
+	if err := Load(&cfg, []string{"-config", flagged}); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Host != "flagged" {
+		t.Errorf("Server.Host = %q, want -config to win over CONFIG_FILE", cfg.Server.Host)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown key", content: "greting: typo\n", wantErr: "greting"},
+		{name: "malformed", content: "greeting: [\n", wantErr: "config.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t, testEnv...)
+			var cfg testConfig
+			err := Load(&cfg, []string{"-config", writeFile(t, "config.yaml", tt.content)})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	clearEnv(t, testEnv...)
+	var cfg testConfig
+	if err := Load(&cfg, []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load with a missing file = %v, want os.ErrNotExist", err)
+	}
+
+	empty := writeFile(t, "empty.yaml", "")
+	if err := Load(&cfg, []string{"-config", empty}); err != nil {
+		t.Errorf("Load with an empty file = %v, want nil", err)
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	secret := writeFile(t, "greeting", "from file\r\n")
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr string
+	}{
+		{name: "file", env: map[string]string{"GREETING_FILE": secret}, want: "from file"},
+		{
+			name:    "both set",
+			env:     map[string]string{"GREETING": "direct", "GREETING_FILE": secret},
+			wantErr: "only one of GREETING and GREETING_FILE may be set",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"GREETING_FILE": filepath.Join(t.TempDir(), "missing")},
+			wantErr: "GREETING_FILE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t, testEnv...)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var cfg testConfig
+			err := Load(&cfg, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load = %v, want an error mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Greeting != tt.want {
+				t.Errorf("Greeting = %q, want %q", cfg.Greeting, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadNestedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want server
+	}{
+		{
+			name: "defaults",
+			want: server{Host: "localhost", Limits: limits{Burst: 10}},
+		},
+		{
+			name: "env joins keys with underscores",
+			env:  map[string]string{"SERVER_HOST": "api", "SERVER_LIMITS_BURST": "20"},
+			want: server{Host: "api", Limits: limits{Burst: 20}},
+		},
+		{
+			name: "env tag replaces the derived name",
+			env:  map[string]string{"CONFIGTEST_TOKEN": "tagged", "SERVER_TOKEN": "derived"},
+			want: server{Host: "localhost", Token: "tagged", Limits: limits{Burst: 10}},
+		},
+		{
+			name: "flags join keys with dashes",
+			args: []string{"-server-host", "api", "-server-limits-burst", "30"},
+			want: server{Host: "api", Limits: limits{Burst: 30}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t, testEnv...)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var cfg testConfig
+			if err := Load(&cfg, tt.args); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server != tt.want {
+				t.Errorf("Server = %+v, want %+v", cfg.Server, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadParseErrors(t *testing.T) {
+	clearEnv(t, testEnv...)
+	t.Setenv("TIMEOUT", "soon")
+	t.Setenv("SERVER_LIMITS_BURST", "many")
+
+	var cfg testConfig
+	err := Load(&cfg, []string{"-timeout", "later"})
+	if err == nil {
+		t.Fatal("Load accepted malformed values")
+	}
+	for _, want := range []string{"TIMEOUT:", "SERVER_LIMITS_BURST:", "flag -timeout:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load error %q does not report %q", err, want)
+		}
+	}
+}
+
+type checkedConfig struct {
+	Min int `yaml:"min" default:"1" validate:"min=0"`
+	Max int `yaml:"max" default:"2" validate:"min=0"`
+
+	validated bool
+}
+
+func (c *checkedConfig) Validate() error {
+	c.validated = true
+	if c.Min > c.Max {
+		return errors.New("min must not exceed max")
+	}
+	return nil
+}
+
+func TestLoadValidator(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantErr       string
+		wantValidated bool
+	}{
+		{name: "valid", wantValidated: true},
+		{name: "cross-field rule", args: []string{"-min", "3"}, wantErr: "min must not exceed max", wantValidated: true},
+		{name: "field rule first", args: []string{"-min", "-1", "-max", "-2"}, wantErr: "min must be at least 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t, "CONFIG_FILE", "MIN", "MAX")
+			var cfg checkedConfig
+			err := Load(&cfg, tt.args)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Load = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+			if cfg.validated != tt.wantValidated {
+				t.Errorf("Validate called = %v, want %v", cfg.validated, tt.wantValidated)
+			}
+		})
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	clearEnv(t, testEnv...)
+	t.Setenv("GREETING", "ignored")
+
+	var cfg testConfig
+	if err := Defaults(&cfg); err != nil {
+		t.Fatalf("Defaults: %v", err)
+	}
+	if cfg.Greeting != "default" || cfg.Timeout != time.Second || cfg.Server.Limits.Burst != 10 {
+		t.Errorf("Defaults = %+v, want only default tags applied", cfg)
+	}
+}
+
+func TestDefaultsRejectsInvalidStructs(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     any
+		wantErr string
+	}{
+		{name: "not a pointer", dst: testConfig{}, wantErr: "need a pointer to a struct"},
+		{name: "pointer to non-struct", dst: new(string), wantErr: "need a pointer to a struct"},
+		{name: "missing yaml tag", dst: &struct{ Name string }{}, wantErr: "has no yaml tag"},
+		{name: "unsupported type", dst: &struct {
+			Ports []int `yaml:"ports"`
+		}{}, wantErr: "unsupported type []int"},
+		{name: "invalid default", dst: &struct {
+			Port int `yaml:"port" default:"http"`
+		}{}, wantErr: "invalid default for port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Defaults(tt.dst)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Defaults = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
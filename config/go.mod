@@ -0,0 +1,5 @@
+module github.com/rrxshxd/assignment1_advProg2/config
+
+go 1.23.4
+
+require gopkg.in/yaml.v3 v3.0.1
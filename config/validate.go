@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func validate(f field) error {
+	if f.rules == "" {
+		return nil
+	}
+
+	for _, rule := range strings.Split(f.rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			if f.value.IsZero() {
+				return fmt.Errorf("%s is required (set %s, %s_FILE, -%s or %q in the config file)", f.key, f.env, f.env, f.flag, f.key)
+			}
+		case "oneof":
+			allowed := strings.Fields(arg)
+			if value := fmt.Sprint(f.value.Interface()); !slices.Contains(allowed, value) {
+				return fmt.Errorf("%s must be one of %s, got %q", f.key, strings.Join(allowed, ", "), value)
+			}
+		case "min":
+			if err := checkMin(f, arg); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: unknown validation rule %q", f.key, name)
+		}
+	}
+	return nil
+}
+
+func checkMin(f field, arg string) error {
+	if f.value.Type() == durationType {
+		min, err := time.ParseDuration(arg)
+		if err != nil {
+			return fmt.Errorf("%s: invalid min rule: %w", f.key, err)
+		}
+		if time.Duration(f.value.Int()) < min {
+			return fmt.Errorf("%s must be at least %s, got %s", f.key, min, time.Duration(f.value.Int()))
+		}
+		return nil
+	}
+
+	min, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Errorf("%s: invalid min rule: %w", f.key, err)
+	}
+
+	var got float64
+	switch f.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(f.value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(f.value.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = f.value.Float()
+	case reflect.String, reflect.Slice:
+		got = float64(f.value.Len())
+	default:
+		return fmt.Errorf("%s: min rule does not apply to %s", f.key, f.value.Type())
+	}
+
+	if got < min {
+		return fmt.Errorf("%s must be at least %s, got %s", f.key, arg, strconv.FormatFloat(got, 'f', -1, 64))
+	}
+	return nil
+}
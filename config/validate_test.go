@@ -0,0 +1,114 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     any
+		wantErr string
+	}{
+		{name: "no rules", dst: &struct {
+			Name string `yaml:"name"`
+		}{}},
+
+		{name: "required set", dst: &struct {
+			Name string `yaml:"name" default:"svc" validate:"required"`
+		}{}},
+		{name: "required missing", dst: &struct {
+			Name string `yaml:"name" validate:"required"`
+		}{}, wantErr: `name is required (set NAME, NAME_FILE, -name or "name" in the config file)`},
+		{name: "required zero number", dst: &struct {
+			Port int `yaml:"port" default:"0" validate:"required"`
+		}{}, wantErr: "port is required"},
+		{name: "required empty list", dst: &struct {
+			Origins []string `yaml:"origins" default:"," validate:"required"`
+		}{}, wantErr: "origins is required"},
+
+		{name: "oneof allowed", dst: &struct {
+			Storage string `yaml:"storage" default:"memory" validate:"oneof=postgres memory"`
+		}{}},
+		{name: "oneof rejected", dst: &struct {
+			Storage string `yaml:"storage" default:"mysql" validate:"oneof=postgres memory"`
+		}{}, wantErr: `storage must be one of postgres, memory, got "mysql"`},
+		{name: "oneof number", dst: &struct {
+			Level int `yaml:"level" default:"2" validate:"oneof=1 2 3"`
+		}{}},
+
+		{name: "min int", dst: &struct {
+			Workers int `yaml:"workers" default:"1" validate:"min=1"`
+		}{}},
+		{name: "min int below", dst: &struct {
+			Workers int `yaml:"workers" default:"0" validate:"min=1"`
+		}{}, wantErr: "workers must be at least 1, got 0"},
+		{name: "min uint below", dst: &struct {
+			Port uint `yaml:"port" default:"80" validate:"min=1024"`
+		}{}, wantErr: "port must be at least 1024, got 80"},
+		{name: "min float below", dst: &struct {
+			Ratio float64 `yaml:"ratio" default:"0.5" validate:"min=0.75"`
+		}{}, wantErr: "ratio must be at least 0.75, got 0.5"},
+		{name: "min string length", dst: &struct {
+			Secret string `yaml:"secret" default:"short" validate:"min=8"`
+		}{}, wantErr: "secret must be at least 8, got 5"},
+		{name: "min list length", dst: &struct {
+			Hosts []string `yaml:"hosts" default:"a,b" validate:"min=2"`
+		}{}},
+		{name: "min duration", dst: &struct {
+			Timeout time.Duration `yaml:"timeout" default:"1s" validate:"min=1s"`
+		}{}},
+		{name: "min duration below", dst: &struct {
+			Timeout time.Duration `yaml:"timeout" default:"500ms" validate:"min=1s"`
+		}{}, wantErr: "timeout must be at least 1s, got 500ms"},
+		{name: "min duration invalid argument", dst: &struct {
+			Timeout time.Duration `yaml:"timeout" validate:"min=1"`
+		}{}, wantErr: "timeout: invalid min rule"},
+		{name: "min invalid argument", dst: &struct {
+			Workers int `yaml:"workers" validate:"min=one"`
+		}{}, wantErr: "workers: invalid min rule"},
+		{name: "min unsupported type", dst: &struct {
+			Debug bool `yaml:"debug" validate:"min=1"`
+		}{}, wantErr: "debug: min rule does not apply to bool"},
+
+		{name: "several rules", dst: &struct {
+			Storage string `yaml:"storage" default:"memory" validate:"required, oneof=postgres memory"`
+		}{}},
+		{name: "first failing rule wins", dst: &struct {
+			Storage string `yaml:"storage" validate:"required,oneof=postgres memory"`
+		}{}, wantErr: "storage is required"},
+		{name: "unknown rule", dst: &struct {
+			Name string `yaml:"name" validate:"max=3"`
+		}{}, wantErr: `name: unknown validation rule "max"`},
+
+		{name: "nested key", dst: &struct {
+			DB struct {
+				URL string `yaml:"url" validate:"required"`
+			} `yaml:"db"`
+		}{}, wantErr: "db.url is required (set DB_URL, DB_URL_FILE, -db-url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := defaults(tt.dst)
+			if err != nil {
+				t.Fatalf("defaults: %v", err)
+			}
+			if len(fields) != 1 {
+				t.Fatalf("defaults returned %d fields, want 1", len(fields))
+			}
+
+			err = validate(fields[0])
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     any
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{name: "string", dst: new(string), raw: " as is ", want: " as is "},
+		{name: "bool", dst: new(bool), raw: "true", want: true},
+		{name: "bool short", dst: new(bool), raw: "0", want: false},
+		{name: "bool invalid", dst: new(bool), raw: "yes", wantErr: true},
+		{name: "int", dst: new(int), raw: "-42", want: -42},
+		{name: "int invalid", dst: new(int), raw: "4.2", wantErr: true},
+		{name: "int8 overflow", dst: new(int8), raw: "128", wantErr: true},
+		{name: "uint", dst: new(uint16), raw: "8080", want: uint16(8080)},
+		{name: "uint negative", dst: new(uint), raw: "-1", wantErr: true},
+		{name: "float", dst: new(float64), raw: "0.25", want: 0.25},
+		{name: "float invalid", dst: new(float32), raw: "quarter", wantErr: true},
+		{name: "duration", dst: new(time.Duration), raw: "1m30s", want: 90 * time.Second},
+		{name: "duration without unit", dst: new(time.Duration), raw: "30", wantErr: true},
+		{name: "duration invalid", dst: new(time.Duration), raw: "soon", wantErr: true},
+		{name: "list", dst: new([]string), raw: "a,b,c", want: []string{"a", "b", "c"}},
+		{name: "list trims items", dst: new([]string), raw: " a , b ", want: []string{"a", "b"}},
+		{name: "list drops blanks", dst: new([]string), raw: "a,,b,", want: []string{"a", "b"}},
+		{name: "list single", dst: new([]string), raw: "a", want: []string{"a"}},
+		{name: "list empty", dst: new([]string), raw: "", want: []string(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.dst).Elem()
+			err := setValue(v, tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("setValue(%q) = %v, want an error", tt.raw, v.Interface())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setValue(%q): %v", tt.raw, err)
+			}
+			if got := v.Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setValue(%q) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		value any
+		want  bool
+	}{
+		{"", true},
+		{0, true},
+		{uint8(0), true},
+		{0.0, true},
+		{false, true},
+		{time.Duration(0), true},
+		{[]string(nil), true},
+		{[]int(nil), false},
+		{map[string]string(nil), false},
+		{(*string)(nil), false},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.value)
+		if got := supported(typ); got != tt.want {
+			t.Errorf("supported(%s) = %v, want %v", typ, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package databasetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/database"
+	"os"
+	"testing"
+	"time"
+)
+
+// Main runs a repository package's integration tests against the server
+// from Postgres, with schemaFile applied, and returns the exit code for
+// os.Exit. db points at the connection pool while the tests run. Packages
+// call it from TestMain and run their tests with
+//
+//	go test -tags integration ./internal/repository/postgres
+func Main(m *testing.M, schemaFile string, db **sql.DB) int {
+	url, stop, err := Postgres()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "integration tests need Postgres:", err)
+		return 1
+	}
+	defer stop()
+
+	pool, err := database.Connect(context.Background(), url, database.PoolConfig{}, 10, 500*time.Millisecond)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer pool.Close()
+
+	schema, err := os.ReadFile(schemaFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if _, err := pool.Exec(string(schema)); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to apply schema:", err)
+		return 1
+	}
+
+	*db = pool
+	return m.Run()
+}
+
+// Truncate empties tables and restarts their ID sequences so every contract
+// subtest starts from an empty store.
+func Truncate(t testing.TB, db *sql.DB, tables string) {
+	t.Helper()
+	if _, err := db.Exec("TRUNCATE " + tables + " RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatalf("truncate %s: %v", tables, err)
+	}
+}
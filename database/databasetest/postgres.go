@@ -0,0 +1,92 @@
+// Package databasetest provides a Postgres server for the services'
+// integration tests.
+package databasetest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Postgres returns a connection URL for integration tests and a function that
+// releases the server. TEST_DATABASE_URL is used as is when set; otherwise a
+// throwaway cluster is started with the initdb and pg_ctl binaries found in
+// PG_BIN or on PATH, and removed again by stop.
+func Postgres() (url string, stop func(), err error) {
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		return url, func() {}, nil
+	}
+
+	initdb, err := pgBinary("initdb")
+	if err != nil {
+		return "", nil, err
+	}
+	pgCtl, err := pgBinary("pg_ctl")
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "pgtest")
+	if err != nil {
+		return "", nil, err
+	}
+	data := filepath.Join(dir, "data")
+	cleanup := func() { os.RemoveAll(dir) }
+
+	if err := run(initdb, "-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync"); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	port, err := freePort()
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	if err := run(pgCtl, "-D", data, "-l", filepath.Join(dir, "postgres.log"), "-o", options, "-w", "start"); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	stop = func() {
+		run(pgCtl, "-D", data, "-m", "immediate", "-w", "stop")
+		cleanup()
+	}
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port), stop, nil
+}
+
+func pgBinary(name string) (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", errors.New("set TEST_DATABASE_URL, or PG_BIN or PATH so that " + name + " can be found")
+	}
+	return path, nil
+}
+
+func run(name string, args ...string) error {
+	var out bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w\n%s", filepath.Base(name), err, out.String())
+	}
+	return nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
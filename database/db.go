@@ -0,0 +1,106 @@
+// Package database opens the services' Postgres connection pools and routes
+// reads to an optional replica.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	_ "github.com/lib/pq"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PoolConfig tunes a connection pool. Zero values keep the database/sql
+// defaults, and a zero StatementTimeout leaves the server setting alone.
+type PoolConfig struct {
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration
+}
+
+// Connect opens a connection pool and waits for the database to answer a
+// ping, retrying up to attempts times so the service can start alongside it.
+func Connect(ctx context.Context, databaseURL string, pool PoolConfig, attempts int, delay time.Duration) (*sql.DB, error) {
+	db, err := open(databaseURL, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return db, nil
+		}
+		if attempt >= attempts {
+			break
+		}
+
+		logging.FromContext(ctx).WarnContext(ctx, "database not ready", "attempt", attempt, "attempts", attempts, "error", err)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("database unreachable after %d attempts: %w", attempts, err)
+}
+
+func open(databaseURL string, pool PoolConfig) (*sql.DB, error) {
+	dsn, err := withStatementTimeout(databaseURL, pool.StatementTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if pool.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// withStatementTimeout sets statement_timeout as a startup parameter, which
+// lib/pq forwards to the server for every connection in the pool. Both URL and
+// key=value connection strings are accepted.
+func withStatementTimeout(dsn string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return dsn, nil
+	}
+	millis := strconv.FormatInt(timeout.Milliseconds(), 10)
+
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " statement_timeout=" + millis, nil
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		// url.Error repeats the input, which would leak the password.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("invalid database URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("statement_timeout", millis)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"io"
+	"net"
+	"strings"
+)
+
+// DB is the primary connection pool plus an optional read replica. Queries go
+// to the primary unless a repository explicitly routes them through Read.
+type DB struct {
+	*sql.DB
+	replica    *sql.DB
+	onFallback func()
+}
+
+// NewDB wraps primary; replica may be nil. onFallback, when set, is called
+// each time a read is retried on the primary, typically to count it.
+func NewDB(primary, replica *sql.DB, onFallback func()) *DB {
+	return &DB{DB: primary, replica: replica, onFallback: onFallback}
+}
+
+// OpenReplica opens the replica pool. Unlike Connect it does not wait for the
+// replica: reads fall back to the primary until it becomes reachable.
+func OpenReplica(ctx context.Context, replicaURL string, pool PoolConfig) (*sql.DB, error) {
+	db, err := open(replicaURL, pool)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "read replica not reachable yet", "error", err)
+	}
+	return db, nil
+}
+
+func (db *DB) Close() error {
+	if db.replica != nil {
+		db.replica.Close()
+	}
+	return db.DB.Close()
+}
+
+// Read runs fn on the replica if there is one, and again on the primary when
+// the replica could not serve the query. fn must not keep state across calls.
+func (db *DB) Read(ctx context.Context, fn func(*sql.DB) error) error {
+	if db.replica == nil {
+		return fn(db.DB)
+	}
+
+	err := fn(db.replica)
+	if !replicaUnavailable(ctx, err) {
+		return err
+	}
+
+	if db.onFallback != nil {
+		db.onFallback()
+	}
+	logging.FromContext(ctx).WarnContext(ctx, "read replica failed, retrying on primary", "error", err)
+	return fn(db.DB)
+}
+
+// replicaUnavailable reports whether err came from the replica or the
+// connection to it rather than from the query, so retrying on the primary may
+// succeed. Query errors, scan errors and cancellations are returned as they
+// are.
+func replicaUnavailable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		code := string(pqErr.Code)
+		// 57014 is a statement timeout or cancel request, which the primary
+		// would hit just the same.
+		if code == "57014" {
+			return false
+		}
+		// 08: connection exception, 53: insufficient resources, 57: operator
+		// intervention such as shutdown, 40001: cancelled by recovery conflict.
+		return strings.HasPrefix(code, "08") || strings.HasPrefix(code, "53") ||
+			strings.HasPrefix(code, "57") || code == "40001"
+	}
+
+	// Errors that never reached the server: the replica refused or dropped the
+	// connection, or the pool could not hand out a usable one.
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
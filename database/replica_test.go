@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestReplicaUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"success", nil, false},
+		{"no rows", sql.ErrNoRows, false},
+		{"scan error", errors.New(`sql: Scan error on column index 0, name "id": converting NULL to uint is unsupported`), false},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"syntax error", &pq.Error{Code: "42601"}, false},
+		{"statement timeout", &pq.Error{Code: "57014"}, false},
+		{"connection failure", &pq.Error{Code: "08006"}, true},
+		{"too many connections", &pq.Error{Code: "53300"}, true},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, true},
+		{"recovery conflict", &pq.Error{Code: "40001"}, true},
+		{"wrapped server error", fmt.Errorf("list rows: %w", &pq.Error{Code: "57P03"}), true},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"bad connection", driver.ErrBadConn, true},
+		{"connection done", sql.ErrConnDone, true},
+		{"connection dropped", io.ErrUnexpectedEOF, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replicaUnavailable(context.Background(), tt.err); got != tt.want {
+				t.Errorf("replicaUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if replicaUnavailable(ctx, driver.ErrBadConn) {
+		t.Error("replicaUnavailable retried a query whose context was cancelled")
+	}
+}
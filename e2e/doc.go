@@ -0,0 +1,3 @@
+// Package e2e holds end-to-end scenarios that drive the gateway and every
+// backing service together. See the harness package for how they are booted.
+package e2e
@@ -0,0 +1,282 @@
+package harness
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the public gateway API. It is immutable; WithToken and
+// WithAPIKey return copies that authenticate as a user.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	apiKey     string
+}
+
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), httpClient: httpClient}
+}
+
+// WithToken returns a client that sends token as a bearer token.
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.token, clone.apiKey = token, ""
+	return &clone
+}
+
+// WithAPIKey returns a client that authenticates with an X-API-Key header.
+func (c *Client) WithAPIKey(key string) *Client {
+	clone := *c
+	clone.token, clone.apiKey = "", key
+	return &clone
+}
+
+// APIError is returned for any response outside the 2xx range.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// StatusCode returns the HTTP status of an *APIError, or 0 for other errors.
+func StatusCode(err error) int {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+type User struct {
+	ID       uint64 `json:"id,string"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type Session struct {
+	UserID uint64 `json:"userId,string"`
+	Token  string `json:"token"`
+}
+
+type APIKey struct {
+	ID     uint64   `json:"id,string"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	// Key is the plaintext key, only known right after creation.
+	Key string `json:"-"`
+}
+
+type Product struct {
+	ID          uint    `json:"id,omitempty"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+}
+
+// CreateOrderItem is an order line as clients send it; the order service
+// prices it from the inventory.
+type CreateOrderItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+type OrderItem struct {
+	ProductID uint    `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+type Order struct {
+	ID        uint        `json:"id,omitempty"`
+	UserID    uint        `json:"user_id"`
+	Items     []OrderItem `json:"items"`
+	Total     float64     `json:"total"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type OrderItemDetails struct {
+	OrderItem
+	Name string `json:"name"`
+}
+
+type OrderDetails struct {
+	ID       uint               `json:"id"`
+	UserID   uint               `json:"user_id"`
+	Status   string             `json:"status"`
+	Total    float64            `json:"total"`
+	Items    []OrderItemDetails `json:"items"`
+	Warnings []string           `json:"warnings"`
+}
+
+// Register creates an account. The user service also signs the new user in,
+// but callers normally go through Login like a real client would.
+func (c *Client) Register(ctx context.Context, email, username, password string) (*User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	body := map[string]string{"email": email, "username": username, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/v1/users", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+func (c *Client) Login(ctx context.Context, email, password string) (*Session, error) {
+	var session Session
+	body := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/v1/auth/login", body, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CreateAPIKey needs a client carrying the owner's token.
+func (c *Client) CreateAPIKey(ctx context.Context, userID uint64, name string, scopes ...string) (*APIKey, error) {
+	var resp struct {
+		APIKey APIKey `json:"apiKey"`
+		Key    string `json:"key"`
+	}
+	body := map[string]interface{}{"name": name, "scopes": scopes}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/v1/users/%d/api-keys", userID), body, &resp); err != nil {
+		return nil, err
+	}
+	resp.APIKey.Key = resp.Key
+	return &resp.APIKey, nil
+}
+
+// ListProducts browses the catalogue. query takes the inventory filters:
+// category, min_price, max_price, name, page and limit.
+func (c *Client) ListProducts(ctx context.Context, query url.Values) ([]Product, error) {
+	var resp struct {
+		Data []Product `json:"data"`
+	}
+	path := "/inventory/products"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (c *Client) GetProduct(ctx context.Context, id uint) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, http.MethodGet, "/inventory/products/"+strconv.FormatUint(uint64(id), 10), nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CreateOrder places an order owned by the authenticated user.
+func (c *Client) CreateOrder(ctx context.Context, items ...CreateOrderItem) (*Order, error) {
+	var created Order
+	body := map[string]interface{}{"items": items}
+	if err := c.do(ctx, http.MethodPost, "/orders/", body, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) GetOrder(ctx context.Context, id uint) (*Order, error) {
+	var order Order
+	if err := c.do(ctx, http.MethodGet, "/orders/"+strconv.FormatUint(uint64(id), 10), nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (c *Client) UpdateOrderStatus(ctx context.Context, id uint, status string) error {
+	body := map[string]string{"status": status}
+	return c.do(ctx, http.MethodPatch, "/orders/"+strconv.FormatUint(uint64(id), 10), body, nil)
+}
+
+func (c *Client) CancelOrder(ctx context.Context, id uint) error {
+	return c.UpdateOrderStatus(ctx, id, "cancelled")
+}
+
+func (c *Client) GetOrderDetails(ctx context.Context, id uint) (*OrderDetails, error) {
+	var details OrderDetails
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/orders/%d/details", id), nil, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// do sends body as JSON and decodes a successful response into out, which
+// may be nil to discard it.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: errorMessage(resp.Body)}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorMessage extracts the message from the {"error": ...} bodies of the
+// gin services or the {"message": ...} bodies of the user API.
+func errorMessage(body io.Reader) string {
+	raw, _ := io.ReadAll(io.LimitReader(body, 64<<10))
+	var decoded struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(raw, &decoded) == nil {
+		if decoded.Error != "" {
+			return decoded.Error
+		}
+		if decoded.Message != "" {
+			return decoded.Message
+		}
+	}
+	return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,117 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/rrxshxd/assignment1_advProg2/e2e/harness"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDocuments(t *testing.T) {
+	h := harness.Start(t)
+
+	tests := []struct {
+		name    string
+		baseURL string
+		paths   []string
+	}{
+		{"gateway", h.GatewayURL, []string{"/inventory/products", "/orders/{id}", "/api/orders/{id}/details"}},
+		{"inventory", h.InventoryURL, []string{"/products", "/products/create", "/products/{id}"}},
+		{"order", h.OrderURL, []string{"/orders", "/orders/{id}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(tt.baseURL + "/openapi.json")
+			if err != nil {
+				t.Fatalf("GET /openapi.json: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var doc struct {
+				OpenAPI string                     `json:"openapi"`
+				Paths   map[string]json.RawMessage `json:"paths"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+				t.Fatalf("decode document: %v", err)
+			}
+			if !strings.HasPrefix(doc.OpenAPI, "3.") {
+				t.Errorf("openapi = %q, want a 3.x document", doc.OpenAPI)
+			}
+			for _, path := range tt.paths {
+				if _, ok := doc.Paths[path]; !ok {
+					t.Errorf("document does not describe %s", path)
+				}
+			}
+
+			ui, err := http.Get(tt.baseURL + "/docs")
+			if err != nil {
+				t.Fatalf("GET /docs: %v", err)
+			}
+			page, _ := io.ReadAll(ui.Body)
+			ui.Body.Close()
+			if ui.StatusCode != http.StatusOK || !bytes.Contains(page, []byte("/openapi.json")) {
+				t.Errorf("GET /docs = %d, want a Swagger UI page loading /openapi.json", ui.StatusCode)
+			}
+			if csp := ui.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "script-src https://unpkg.com 'sha256-") {
+				t.Errorf("Swagger UI Content-Security-Policy = %q, want the UI's script sources allowed", csp)
+			}
+		})
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	h := harness.Start(t)
+	adminToken := h.AdminToken(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		body    string
+		message string
+	}{
+		{"negative price via gateway", http.MethodPost, h.GatewayURL + "/inventory/products/create", `{"name":"Pen","category":"stationery","price":-1}`, "field price"},
+		{"missing name", http.MethodPost, h.InventoryURL + "/products/create", `{"category":"stationery","price":1}`, "name"},
+		{"wrong type", http.MethodPatch, h.InventoryURL + "/products/1", `{"stock":"many"}`, "field stock"},
+		{"null field", http.MethodPatch, h.GatewayURL + "/inventory/products/1", `{"price":null}`, "field price"},
+		{"negative stock via gateway", http.MethodPut, h.GatewayURL + "/inventory/products/1", `{"name":"Pen","category":"stationery","price":1,"stock":-3}`, "field stock"},
+		{"page too large", http.MethodGet, h.GatewayURL + "/inventory/products?limit=500", "", `query parameter "limit"`},
+		{"non-numeric filter", http.MethodGet, h.InventoryURL + "/products?min_price=cheap", "", `query parameter "min_price"`},
+		{"non-numeric ID", http.MethodGet, h.GatewayURL + "/orders/abc", "", `path parameter "id"`},
+		{"unknown status", http.MethodPatch, h.OrderURL + "/orders/1", `{"status":"shipped"}`, "field status"},
+		{"no items", http.MethodPost, h.GatewayURL + "/orders/", `{"items":[]}`, "field items"},
+		{"zero quantity", http.MethodPost, h.OrderURL + "/orders", `{"items":[{"product_id":1,"quantity":0,"price":1}]}`, "field items.0.quantity"},
+		{"missing product", http.MethodPost, h.OrderURL + "/orders", `{"items":[{"quantity":1,"price":1}]}`, "product_id"},
+		{"missing user", http.MethodGet, h.OrderURL + "/orders", "", `query parameter "user_id"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if strings.HasPrefix(tt.url, h.GatewayURL) {
+				req.Header.Set("Authorization", "Bearer "+adminToken)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s: %v", tt.method, tt.url, err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				Error string `json:"error"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body.Error, tt.message) {
+				t.Errorf("%s %s = %d %q, want 400 mentioning %q", tt.method, tt.url, resp.StatusCode, body.Error, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/e2e/harness"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/dto"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRegisterLoginBrowseOrderCancel(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	products := h.SeedProducts(t,
+		dto.CreateProductRequest{Name: "Mechanical keyboard", Category: "peripherals", Price: 80, Stock: 5},
+		dto.CreateProductRequest{Name: "Wireless mouse", Category: "peripherals", Price: 25, Stock: 10},
+		dto.CreateProductRequest{Name: "Desk lamp", Category: "furniture", Price: 40, Stock: 2},
+	)
+
+	registered, err := h.Gateway.Register(ctx, "ada@example.com", "ada", "Sup3rSecret")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	session, err := h.Gateway.Login(ctx, "ada@example.com", "Sup3rSecret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if session.UserID != registered.ID || session.Token == "" {
+		t.Fatalf("Login = %+v, want a token for user %d", session, registered.ID)
+	}
+
+	key, err := h.Gateway.WithToken(session.Token).CreateAPIKey(ctx, session.UserID, "e2e", "inventory:read", "orders:read", "orders:write")
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	shopper := h.Gateway.WithAPIKey(key.Key)
+
+	peripherals, err := shopper.ListProducts(ctx, url.Values{"category": {"peripherals"}})
+	if err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if len(peripherals) != 2 || peripherals[0].ID != products[0].ID || peripherals[1].ID != products[1].ID {
+		t.Fatalf("ListProducts(peripherals) = %+v, want the keyboard and the mouse", peripherals)
+	}
+
+	keyboard, err := shopper.GetProduct(ctx, products[0].ID)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+
+	order, err := shopper.CreateOrder(ctx, harness.CreateOrderItem{ProductID: keyboard.ID, Quantity: 1},
+		harness.CreateOrderItem{ProductID: peripherals[1].ID, Quantity: 2},
+	)
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if order.Status != "pending" || order.Total != 130 || order.CreatedAt.IsZero() {
+		t.Errorf("CreateOrder = %+v, want a pending order totalling 130 with a creation time", order)
+	}
+	stockOf := func(id uint) int {
+		t.Helper()
+		product, err := h.Inventory.GetProduct(ctx, id)
+		if err != nil {
+			t.Fatalf("GetProduct(%d) from the inventory: %v", id, err)
+		}
+		return product.Stock
+	}
+	if keyboards, mice := stockOf(keyboard.ID), stockOf(peripherals[1].ID); keyboards != 4 || mice != 8 {
+		t.Errorf("stock after ordering = %d keyboards and %d mice, want 4 and 8 reserved", keyboards, mice)
+	}
+	if _, err := shopper.CreateOrder(ctx, harness.CreateOrderItem{ProductID: products[2].ID, Quantity: 3}); harness.StatusCode(err) != http.StatusConflict {
+		t.Errorf("CreateOrder beyond the stock error = %v, want 409", err)
+	}
+	if _, err := shopper.CreateOrder(ctx, harness.CreateOrderItem{ProductID: 999, Quantity: 1}); harness.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("CreateOrder of an unknown product error = %v, want 400", err)
+	}
+
+	details, err := shopper.GetOrderDetails(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetOrderDetails: %v", err)
+	}
+	if len(details.Items) != 2 || details.Items[0].Name != "Mechanical keyboard" || details.Total != 130 {
+		t.Errorf("GetOrderDetails = %+v, want both items named and a total of 130", details)
+	}
+
+	if err := shopper.CancelOrder(ctx, order.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	cancelled, err := shopper.GetOrder(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if cancelled.Status != "cancelled" {
+		t.Errorf("order status after cancel = %q, want cancelled", cancelled.Status)
+	}
+	if keyboards, mice := stockOf(keyboard.ID), stockOf(peripherals[1].ID); keyboards != 5 || mice != 10 {
+		t.Errorf("stock after cancelling = %d keyboards and %d mice, want 5 and 10 released", keyboards, mice)
+	}
+}
+
+func TestAPIKeyScopesAreEnforced(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	h.SeedProducts(t, dto.CreateProductRequest{Name: "Cable", Category: "accessories", Price: 5, Stock: 50})
+
+	if _, err := h.Gateway.Register(ctx, "grace@example.com", "grace", "Sup3rSecret"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	session, err := h.Gateway.Login(ctx, "grace@example.com", "Sup3rSecret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	key, err := h.Gateway.WithToken(session.Token).CreateAPIKey(ctx, session.UserID, "read only", "inventory:read")
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	reader := h.Gateway.WithAPIKey(key.Key)
+
+	if _, err := reader.ListProducts(ctx, nil); err != nil {
+		t.Errorf("ListProducts with inventory:read: %v", err)
+	}
+	if _, err := reader.CreateOrder(ctx, harness.CreateOrderItem{ProductID: 1, Quantity: 1}); harness.StatusCode(err) != http.StatusForbidden {
+		t.Errorf("CreateOrder without orders:write error = %v, want 403", err)
+	}
+	if _, err := h.Gateway.WithAPIKey("not-a-key").ListProducts(ctx, nil); harness.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("ListProducts with an unknown key error = %v, want 401", err)
+	}
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+
+	if _, err := h.Gateway.Register(ctx, "linus@example.com", "linus", "Sup3rSecret"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := h.Gateway.Login(ctx, "linus@example.com", "wrong"); harness.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("Login with a wrong password error = %v, want 401", err)
+	}
+}
+
+func TestGatewayCacheHonorsNoCache(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	products := h.SeedProducts(t, dto.CreateProductRequest{Name: "Lamp", Category: "furniture", Price: 30, Stock: 7})
+
+	getStock := func(cacheControl string) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/inventory/products/%d", h.GatewayURL, products[0].ID), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cacheControl != "" {
+			req.Header.Set("Cache-Control", cacheControl)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET product: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var product dto.Product
+		if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+			t.Fatalf("decode product: %v", err)
+		}
+		return product.Stock
+	}
+
+	if stock := getStock(""); stock != 7 {
+		t.Fatalf("stock = %d, want 7", stock)
+	}
+
+	// Changes made on the inventory service itself, like stock reserved over
+	// gRPC, never pass through the gateway cache.
+	stock := 2
+	if _, err := h.Inventory.UpdateProduct(ctx, products[0].ID, dto.UpdateProductRequest{Stock: &stock}); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+
+	if got := getStock(""); got != 7 {
+		t.Errorf("stock from a fresh cache entry = %d, want the cached 7", got)
+	}
+	for _, directive := range []string{"no-cache", "max-age=0"} {
+		if got := getStock(directive); got != 2 {
+			t.Errorf("stock with Cache-Control: %s = %d, want the current 2", directive, got)
+		}
+	}
+}
+
+func TestGatewayRequiresCredentials(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	products := h.SeedProducts(t, dto.CreateProductRequest{Name: "Mug", Category: "kitchen", Price: 8, Stock: 12})
+	productPath := fmt.Sprintf("/inventory/products/%d", products[0].ID)
+
+	if _, err := h.Gateway.Register(ctx, "alan@example.com", "alan", "Sup3rSecret"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	session, err := h.Gateway.Login(ctx, "alan@example.com", "Sup3rSecret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	send := func(method, path, authorization, body string) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, h.GatewayURL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	customer := "Bearer " + session.Token
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		body          string
+		want          int
+	}{
+		{"anonymous product list", http.MethodGet, "/inventory/products", "", "", http.StatusOK},
+		{"anonymous product", http.MethodGet, productPath, "", "", http.StatusOK},
+		{"anonymous create product", http.MethodPost, "/inventory/products/create", "", `{"name":"Free","category":"kitchen","price":1,"stock":1}`, http.StatusUnauthorized},
+		{"anonymous patch product", http.MethodPatch, productPath, "", `{"price":0.01}`, http.StatusUnauthorized},
+		{"anonymous delete product", http.MethodDelete, productPath, "", "", http.StatusUnauthorized},
+		{"anonymous order", http.MethodGet, "/orders/1", "", "", http.StatusUnauthorized},
+		{"anonymous create order", http.MethodPost, "/orders/", "", `{"items":[{"product_id":1,"quantity":1}]}`, http.StatusUnauthorized},
+		{"anonymous order details", http.MethodGet, "/api/orders/1/details", "", "", http.StatusUnauthorized},
+		{"forged token", http.MethodDelete, productPath, "Bearer not.a.token", "", http.StatusUnauthorized},
+		{"customer deletes product", http.MethodDelete, productPath, customer, "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := send(tt.method, tt.path, tt.authorization, tt.body); got != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+
+	order, err := h.Gateway.WithToken(session.Token).CreateOrder(ctx, harness.CreateOrderItem{ProductID: products[0].ID, Quantity: 1})
+	if err != nil {
+		t.Fatalf("CreateOrder with a customer token: %v", err)
+	}
+	if _, err := h.Gateway.WithToken(session.Token).GetOrder(ctx, order.ID); err != nil {
+		t.Errorf("GetOrder with a customer token: %v", err)
+	}
+}
+
+func TestCustomersOnlyReachTheirOwnOrders(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	products := h.SeedProducts(t, dto.CreateProductRequest{Name: "Kettle", Category: "kitchen", Price: 20, Stock: 5})
+
+	login := func(email, username string) *harness.Session {
+		t.Helper()
+		if _, err := h.Gateway.Register(ctx, email, username, "Sup3rSecret"); err != nil {
+			t.Fatalf("Register %s: %v", username, err)
+		}
+		session, err := h.Gateway.Login(ctx, email, "Sup3rSecret")
+		if err != nil {
+			t.Fatalf("Login %s: %v", username, err)
+		}
+		return session
+	}
+	ownerSession, otherSession := login("edsger@example.com", "edsger"), login("tony@example.com", "tony")
+	owner, other := h.Gateway.WithToken(ownerSession.Token), h.Gateway.WithToken(otherSession.Token)
+
+	// A user_id in the body, once part of the request, must not pick the owner.
+	body := fmt.Sprintf(`{"user_id":%d,"items":[{"product_id":%d,"quantity":1}]}`, ownerSession.UserID, products[0].ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.GatewayURL+"/orders/", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+otherSession.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /orders/: %v", err)
+	}
+	var order harness.Order
+	err = json.NewDecoder(resp.Body).Decode(&order)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusCreated || order.UserID != uint(otherSession.UserID) {
+		t.Fatalf("POST /orders/ naming another user = %d %+v, want 201 owned by the caller %d", resp.StatusCode, order, otherSession.UserID)
+	}
+
+	created, err := owner.CreateOrder(ctx, harness.CreateOrderItem{ProductID: products[0].ID, Quantity: 1})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if _, err := other.GetOrder(ctx, created.ID); harness.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("GetOrder of another user's order error = %v, want 404", err)
+	}
+	if _, err := other.GetOrderDetails(ctx, created.ID); harness.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("GetOrderDetails of another user's order error = %v, want 404", err)
+	}
+	if err := other.CancelOrder(ctx, created.ID); harness.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("CancelOrder of another user's order error = %v, want 404", err)
+	}
+	if err := owner.UpdateOrderStatus(ctx, created.ID, "completed"); harness.StatusCode(err) != http.StatusForbidden {
+		t.Errorf("UpdateOrderStatus(completed) by the owner error = %v, want 403", err)
+	}
+
+	admin := h.Gateway.WithToken(h.AdminToken(t))
+	if err := admin.UpdateOrderStatus(ctx, created.ID, "completed"); err != nil {
+		t.Errorf("UpdateOrderStatus(completed) by an admin: %v", err)
+	}
+	if got, err := owner.GetOrder(ctx, created.ID); err != nil || got.Status != "completed" {
+		t.Errorf("GetOrder by the owner = %+v, %v, want the completed order", got, err)
+	}
+}
+
+func TestAPIKeyScopesAreLimitedByRole(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+
+	if _, err := h.Gateway.Register(ctx, "barbara@example.com", "barbara", "Sup3rSecret"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	session, err := h.Gateway.Login(ctx, "barbara@example.com", "Sup3rSecret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	owner := h.Gateway.WithToken(session.Token)
+
+	tests := []struct {
+		scope string
+		want  int
+	}{
+		{"inventory:write", http.StatusForbidden},
+		{"*", http.StatusForbidden},
+		{"inventory:admin", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if _, err := owner.CreateAPIKey(ctx, session.UserID, "too much", "orders:read", tt.scope); harness.StatusCode(err) != tt.want {
+			t.Errorf("CreateAPIKey(%q) error = %v, want %d", tt.scope, err, tt.want)
+		}
+	}
+	if _, err := owner.CreateAPIKey(ctx, session.UserID, "shopping", "inventory:read", "orders:read", "orders:write"); err != nil {
+		t.Errorf("CreateAPIKey with customer scopes: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/e2e/harness"
+	inventoryclient "github.com/rrxshxd/assignment1_advProg2/inventory_service/client"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/dto"
+	orderclient "github.com/rrxshxd/assignment1_advProg2/order_service/client"
+	orderdto "github.com/rrxshxd/assignment1_advProg2/order_service/dto"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInventoryClient(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	seeded := h.SeedProducts(t,
+		dto.CreateProductRequest{Name: "Pen", Category: "stationery", Price: 1, Stock: 100},
+		dto.CreateProductRequest{Name: "Notebook", Category: "stationery", Price: 4, Stock: 40},
+		dto.CreateProductRequest{Name: "Stapler", Category: "stationery", Price: 12, Stock: 5},
+		dto.CreateProductRequest{Name: "Ruler", Category: "stationery", Price: 2, Stock: 30},
+		dto.CreateProductRequest{Name: "Chair", Category: "furniture", Price: 90, Stock: 3},
+	)
+
+	var names []string
+	for product, err := range h.Inventory.Products(ctx, dto.ProductQuery{Category: "stationery", Limit: 2}) {
+		if err != nil {
+			t.Fatalf("Products: %v", err)
+		}
+		names = append(names, product.Name)
+	}
+	if len(names) != 4 {
+		t.Errorf("Products(stationery) over pages of 2 = %v, want the 4 stationery products", names)
+	}
+
+	minPrice := 10.0
+	expensive, err := h.Inventory.ListProducts(ctx, dto.ProductQuery{MinPrice: &minPrice})
+	if err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if len(expensive.Data) != 2 {
+		t.Errorf("ListProducts(min_price=10) = %+v, want the stapler and the chair", expensive.Data)
+	}
+
+	price, stock := 1.5, 90
+	updated, err := h.Inventory.UpdateProduct(ctx, seeded[0].ID, dto.UpdateProductRequest{Price: &price, Stock: &stock})
+	if err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if updated.Name != "Pen" || updated.Price != 1.5 || updated.Stock != 90 {
+		t.Errorf("UpdateProduct = %+v, want the pen at 1.5 with 90 in stock", updated)
+	}
+
+	if err := h.Inventory.DeleteProduct(ctx, seeded[4].ID); err != nil {
+		t.Fatalf("DeleteProduct: %v", err)
+	}
+	_, err = h.Inventory.GetProduct(ctx, seeded[4].ID)
+	var apiErr *inventoryclient.Error
+	if !errors.Is(err, inventoryclient.ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Message != "product not found" {
+		t.Errorf("GetProduct after delete error = %v, want ErrNotFound with the server's message", err)
+	}
+
+	if _, err := h.Inventory.CreateProduct(ctx, dto.CreateProductRequest{Name: "Free"}); !errors.Is(err, inventoryclient.ErrBadRequest) {
+		t.Errorf("CreateProduct without category or price error = %v, want ErrBadRequest", err)
+	}
+}
+
+func TestProductPatchAndReplace(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	product := h.SeedProducts(t, dto.CreateProductRequest{
+		Name: "Lamp", Description: "Brass desk lamp", Category: "lighting", Price: 30, Stock: 7,
+	})[0]
+
+	cleared := ""
+	patched, err := h.Inventory.UpdateProduct(ctx, product.ID, dto.UpdateProductRequest{Description: &cleared})
+	if err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if patched.Description != "" || patched.Stock != 7 || patched.Price != 30 || patched.Name != "Lamp" {
+		t.Errorf("UpdateProduct(description only) = %+v, want only the description cleared", patched)
+	}
+
+	before, err := h.Inventory.GetProduct(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+
+	zero := 0
+	patched, err = h.Inventory.UpdateProduct(ctx, product.ID, dto.UpdateProductRequest{Stock: &zero})
+	if err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if patched.Stock != 0 || patched.Price != 30 {
+		t.Errorf("UpdateProduct(stock 0) = %+v, want stock 0 and the price kept", patched)
+	}
+
+	replaced, err := h.Inventory.ReplaceProduct(ctx, product.ID, dto.ReplaceProductRequest{
+		Name: "Floor lamp", Category: "lighting", Price: 80, Stock: &zero,
+	})
+	if err != nil {
+		t.Fatalf("ReplaceProduct: %v", err)
+	}
+	if replaced.Name != "Floor lamp" || replaced.Price != 80 || !replaced.CreatedAt.Equal(before.CreatedAt) || !replaced.UpdatedAt.After(before.UpdatedAt) {
+		t.Errorf("ReplaceProduct = %+v, want the new fields with the original creation time", replaced)
+	}
+	if _, err := h.Inventory.ReplaceProduct(ctx, product.ID+1, dto.ReplaceProductRequest{
+		Name: "Ghost", Category: "lighting", Price: 1, Stock: &zero,
+	}); !errors.Is(err, inventoryclient.ErrNotFound) {
+		t.Errorf("ReplaceProduct of a missing product error = %v, want ErrNotFound", err)
+	}
+
+	negativePrice, negativeStock := -1.0, -1
+	for name, request := range map[string]dto.UpdateProductRequest{
+		"negative price": {Price: &negativePrice},
+		"negative stock": {Stock: &negativeStock},
+	} {
+		if _, err := h.Inventory.UpdateProduct(ctx, product.ID, request); !errors.Is(err, inventoryclient.ErrBadRequest) {
+			t.Errorf("UpdateProduct with a %s error = %v, want ErrBadRequest", name, err)
+		}
+	}
+	if _, err := h.Inventory.ReplaceProduct(ctx, product.ID, dto.ReplaceProductRequest{
+		Name: "Floor lamp", Category: "lighting", Price: 80,
+	}); !errors.Is(err, inventoryclient.ErrBadRequest) {
+		t.Errorf("ReplaceProduct without stock error = %v, want ErrBadRequest", err)
+	}
+
+	got, err := h.Inventory.GetProduct(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if got.Name != "Floor lamp" || got.Price != 80 || got.Stock != 0 {
+		t.Errorf("product after rejected updates = %+v, want the replaced product unchanged", got)
+	}
+}
+
+func TestOrderClient(t *testing.T) {
+	h := harness.Start(t)
+	ctx := context.Background()
+	products := h.SeedProducts(t, dto.CreateProductRequest{Name: "Pen", Category: "stationery", Price: 5, Stock: 10})
+	customer := orderclient.New(h.OrderURL,
+		orderclient.WithHeader(orderdto.UserIDHeader, "3"),
+		orderclient.WithHeader(orderdto.ScopesHeader, "orders:read,orders:write"),
+	)
+
+	created, err := customer.CreateOrder(ctx, orderdto.CreateOrderRequest{
+		Items: []orderdto.CreateOrderItem{{ProductID: products[0].ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if created.ID == 0 || len(created.Items) != 1 || created.Total != 10 || created.Status != orderdto.StatusPending {
+		t.Fatalf("CreateOrder = %+v, want a pending order with an ID, the item and a total of 10", created)
+	}
+
+	var apiErr *orderclient.Error
+	if err := customer.UpdateOrderStatus(ctx, created.ID, orderdto.StatusCompleted); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("UpdateOrderStatus(completed) by the customer error = %v, want 403", err)
+	}
+	if err := customer.CancelOrder(ctx, created.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if _, err := customer.UserOrders(ctx, 4); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("UserOrders of another user error = %v, want 403", err)
+	}
+	orders, err := h.Orders.UserOrders(ctx, 3)
+	if err != nil {
+		t.Fatalf("UserOrders: %v", err)
+	}
+	if len(orders) != 1 || orders[0].ID != created.ID || orders[0].Status != orderdto.StatusCancelled {
+		t.Errorf("UserOrders = %+v, want the cancelled order", orders)
+	}
+
+	if _, err := customer.GetOrder(ctx, created.ID+1); !errors.Is(err, orderclient.ErrNotFound) {
+		t.Errorf("GetOrder of a missing order error = %v, want ErrNotFound", err)
+	}
+	if err := customer.UpdateOrderStatus(ctx, created.ID, "shipped"); !errors.Is(err, orderclient.ErrBadRequest) {
+		t.Errorf("UpdateOrderStatus(shipped) error = %v, want ErrBadRequest", err)
+	}
+	if _, err := orderclient.New(h.OrderURL).GetOrder(ctx, created.ID); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GetOrder without identity headers error = %v, want 401", err)
+	}
+	_, err = customer.CreateOrder(ctx, orderdto.CreateOrderRequest{
+		Items: []orderdto.CreateOrderItem{{ProductID: products[0].ID + 1, Quantity: 1}},
+	})
+	if !errors.Is(err, orderclient.ErrBadRequest) {
+		t.Errorf("CreateOrder of an unknown product error = %v, want ErrBadRequest", err)
+	}
+}
+
+func TestCreateOrderIgnoresServerControlledFields(t *testing.T) {
+	h := harness.Start(t)
+	products := h.SeedProducts(t, dto.CreateProductRequest{Name: "Pen", Category: "stationery", Price: 5, Stock: 10})
+
+	body := fmt.Sprintf(`{"id":99,"user_id":4,"status":"completed","total":1,"created_at":"2001-01-01T00:00:00Z",`+
+		`"updated_at":"2001-01-01T00:00:00Z","items":[{"product_id":%d,"quantity":2,"price":1}]}`, products[0].ID)
+	req, err := http.NewRequest(http.MethodPost, h.OrderURL+"/orders", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(orderdto.UserIDHeader, "3")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /orders: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var created orderdto.Order
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if created.ID != 1 || created.UserID != 3 || created.Status != orderdto.StatusPending || created.Total != 10 {
+		t.Errorf("created order = %+v, want ID 1 owned by the caller, pending and a total of 10", created)
+	}
+	if len(created.Items) != 1 || created.Items[0].Price != 5 {
+		t.Errorf("created order items = %+v, want the inventory price of 5", created.Items)
+	}
+	if time.Since(created.CreatedAt) > time.Minute || !created.UpdatedAt.Equal(created.CreatedAt) {
+		t.Errorf("created order times = %v, %v, want both set to now", created.CreatedAt, created.UpdatedAt)
+	}
+}
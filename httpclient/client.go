@@ -0,0 +1,176 @@
+// Package httpclient is the JSON-over-HTTP transport shared by the services'
+// Go SDKs. Requests that are safe to repeat are retried on network errors and
+// on 429, 502, 503 and 504 responses, backing off exponentially between
+// attempts. Options add headers to every request, so the SDKs can also call
+// the services through the authenticating gateway.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultRetries = 2
+	defaultBackoff = 100 * time.Millisecond
+)
+
+type Client struct {
+	service    string
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	header     http.Header
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default client, which has a 10s timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed idempotent request is repeated
+// and the delay before the first repeat, which doubles on each attempt.
+// Zero retries disables retrying.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithHeader sets a header on every request, replacing an earlier value.
+func WithHeader(name, value string) Option {
+	return func(c *Client) {
+		c.header.Set(name, value)
+	}
+}
+
+// WithBearerToken authenticates every request with an access token.
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithAPIKey authenticates every request with an API key.
+func WithAPIKey(key string) Option {
+	return WithHeader("X-API-Key", key)
+}
+
+// New returns a client for the API at baseURL. service names the API in
+// error messages.
+func New(service, baseURL string, opts ...Option) *Client {
+	c := &Client{
+		service:    service,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+		header:     make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Do sends body as JSON and decodes a successful response into out, which
+// may be nil to discard it. Non-2xx responses are returned as *Error.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = encoded
+	}
+
+	attempts := 1
+	if idempotent(method) {
+		attempts += c.retries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if waitErr := sleep(ctx, c.backoff<<(attempt-1)); waitErr != nil {
+				return waitErr
+			}
+		}
+
+		var retry bool
+		retry, err = c.send(ctx, method, path, payload, out)
+		if !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// send makes one attempt and reports whether a failure is worth retrying.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, out interface{}) (bool, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return false, err
+	}
+	req.Header = c.header.Clone()
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return retryable(resp.StatusCode), c.decodeError(method, path, resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+	return false, json.NewDecoder(resp.Body).Decode(out)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,271 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// server answers with the given statuses in turn, repeating the last one,
+// and records when each request arrived.
+type server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	body     string
+	arrivals []time.Time
+}
+
+func newServer(t *testing.T, body string, statuses ...int) *server {
+	t.Helper()
+	s := &server{statuses: statuses, body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.arrivals = append(s.arrivals, time.Now())
+		status := s.statuses[min(len(s.arrivals), len(s.statuses))-1]
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+		if status < 300 {
+			w.Write([]byte(`{"id":7}`))
+			return
+		}
+		w.Write([]byte(s.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.arrivals)
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		statuses     []int
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, wantAttempts: 1},
+		{name: "retried until success", method: http.MethodGet, statuses: []int{503, 502, 200}, wantAttempts: 3},
+		{name: "retries exhausted", method: http.MethodGet, statuses: []int{429}, wantAttempts: 3, wantErr: ErrUnavailable},
+		{name: "gateway timeout", method: http.MethodDelete, statuses: []int{504, 200}, wantAttempts: 2},
+		{name: "put is idempotent", method: http.MethodPut, statuses: []int{503, 200}, wantAttempts: 2},
+		{name: "post is not retried", method: http.MethodPost, statuses: []int{503}, wantAttempts: 1, wantErr: ErrUnavailable},
+		{name: "patch is not retried", method: http.MethodPatch, statuses: []int{503}, wantAttempts: 1, wantErr: ErrUnavailable},
+		{name: "client errors are not retried", method: http.MethodGet, statuses: []int{404}, wantAttempts: 1, wantErr: ErrNotFound},
+		{name: "server errors are not retried", method: http.MethodGet, statuses: []int{500}, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, "", tt.statuses...)
+			c := New("test", s.URL, WithRetries(2, time.Millisecond))
+
+			var out struct{ ID int }
+			err := c.Do(context.Background(), tt.method, "/things", nil, &out)
+			if got := s.attempts(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+
+			last := tt.statuses[min(tt.wantAttempts, len(tt.statuses))-1]
+			switch {
+			case last < 300:
+				if err != nil || out.ID != 7 {
+					t.Errorf("Do = %v with %+v, want the decoded response", err, out)
+				}
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("Do = %v, want %v", err, tt.wantErr)
+			case err == nil:
+				t.Error("Do succeeded on an error response")
+			}
+		})
+	}
+}
+
+func TestDoWithoutRetries(t *testing.T) {
+	s := newServer(t, "", http.StatusServiceUnavailable)
+	c := New("test", s.URL, WithRetries(0, time.Millisecond))
+
+	if err := c.Do(context.Background(), http.MethodGet, "/things", nil, nil); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Do = %v, want ErrUnavailable", err)
+	}
+	if got := s.attempts(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestDoRetriesNetworkErrors(t *testing.T) {
+	s := newServer(t, "", http.StatusOK)
+	url := s.URL
+	s.Close()
+
+	c := New("test", url, WithRetries(2, time.Millisecond))
+	start := time.Now()
+	if err := c.Do(context.Background(), http.MethodGet, "/things", nil, nil); err == nil {
+		t.Fatal("Do succeeded against a closed server")
+	}
+	if elapsed := time.Since(start); elapsed < 3*time.Millisecond {
+		t.Errorf("Do returned after %s, want it to back off between attempts", elapsed)
+	}
+}
+
+func TestDoBacksOffExponentially(t *testing.T) {
+	const backoff = 20 * time.Millisecond
+	s := newServer(t, "", http.StatusServiceUnavailable)
+	c := New("test", s.URL, WithRetries(3, backoff))
+
+	if err := c.Do(context.Background(), http.MethodGet, "/things", nil, nil); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Do = %v, want ErrUnavailable", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.arrivals) != 4 {
+		t.Fatalf("attempts = %d, want 4", len(s.arrivals))
+	}
+	for i := 1; i < len(s.arrivals); i++ {
+		want := backoff << (i - 1)
+		if gap := s.arrivals[i].Sub(s.arrivals[i-1]); gap < want {
+			t.Errorf("gap before attempt %d = %s, want at least %s", i+1, gap, want)
+		}
+	}
+}
+
+func TestDoStopsWhenContextEndsDuringBackoff(t *testing.T) {
+	s := newServer(t, "", http.StatusServiceUnavailable)
+	c := New("test", s.URL, WithRetries(2, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := c.Do(ctx, http.MethodGet, "/things", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do = %v, want context.DeadlineExceeded", err)
+	}
+	if got := s.attempts(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestDoSendsJSON(t *testing.T) {
+	var got struct {
+		Method, Path, ContentType, Accept string
+		Body                              map[string]string
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Method, got.Path = r.Method, r.URL.Path
+		got.ContentType, got.Accept = r.Header.Get("Content-Type"), r.Header.Get("Accept")
+		json.NewDecoder(r.Body).Decode(&got.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	c := New("test", s.URL+"/")
+	var out struct{ ID int }
+	if err := c.Do(context.Background(), http.MethodPost, "/things", map[string]string{"name": "widget"}, &out); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got.Method != http.MethodPost || got.Path != "/things" || got.Body["name"] != "widget" {
+		t.Errorf("server saw %s %s with %v", got.Method, got.Path, got.Body)
+	}
+	if got.ContentType != "application/json" || got.Accept != "application/json" {
+		t.Errorf("Content-Type = %q, Accept = %q, want application/json", got.ContentType, got.Accept)
+	}
+}
+
+func TestDoSendsConfiguredHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{name: "none", want: map[string]string{"Authorization": "", "X-API-Key": ""}},
+		{name: "bearer token", opts: []Option{WithBearerToken("t0k")}, want: map[string]string{"Authorization": "Bearer t0k"}},
+		{name: "api key", opts: []Option{WithAPIKey("k3y")}, want: map[string]string{"X-API-Key": "k3y", "Authorization": ""}},
+		{name: "custom header", opts: []Option{WithHeader("X-Tenant", "a")}, want: map[string]string{"X-Tenant": "a"}},
+		{name: "later option wins", opts: []Option{WithHeader("X-Tenant", "a"), WithHeader("X-Tenant", "b")}, want: map[string]string{"X-Tenant": "b"}},
+		{name: "accept cannot be replaced", opts: []Option{WithHeader("Accept", "text/plain")}, want: map[string]string{"Accept": "application/json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var seen []http.Header
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				seen = append(seen, r.Header.Clone())
+				mu.Unlock()
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer s.Close()
+
+			c := New("test", s.URL, append(tt.opts, WithRetries(1, time.Millisecond))...)
+			c.Do(context.Background(), http.MethodGet, "/things", nil, nil)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(seen) != 2 {
+				t.Fatalf("attempts = %d, want 2", len(seen))
+			}
+			for i, header := range seen {
+				for name, want := range tt.want {
+					if got := header.Get(name); got != want {
+						t.Errorf("attempt %d: %s = %q, want %q", i+1, name, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+		wantIs      error
+	}{
+		{name: "json body", status: 404, body: `{"error":"product not found"}`, wantMessage: "product not found", wantIs: ErrNotFound},
+		{name: "plain body", status: 400, body: " quantity must be positive\n", wantMessage: "quantity must be positive", wantIs: ErrBadRequest},
+		{name: "json without message", status: 400, body: `{"detail":"x"}`, wantMessage: `{"detail":"x"}`, wantIs: ErrBadRequest},
+		{name: "empty body", status: 409, wantMessage: "Conflict"},
+		{name: "unavailable", status: 429, body: `{"error":"slow down"}`, wantMessage: "slow down", wantIs: ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, tt.body, tt.status)
+			c := New("inventory", s.URL, WithRetries(0, 0))
+
+			err := c.Do(context.Background(), http.MethodGet, "/products/1", nil, nil)
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Do = %v, want *Error", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage {
+				t.Errorf("Error = %d %q, want %d %q", apiErr.StatusCode, apiErr.Message, tt.status, tt.wantMessage)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.wantIs)
+			}
+			for _, other := range []error{ErrBadRequest, ErrNotFound, ErrUnavailable} {
+				if other != tt.wantIs && errors.Is(err, other) {
+					t.Errorf("errors.Is(%v, %v) = true", err, other)
+				}
+			}
+			if want := "inventory: GET /products/1: "; !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("Error() = %q, want prefix %q", err.Error(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrBadRequest  = errors.New("bad request")
+	ErrNotFound    = errors.New("not found")
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// Error is returned for every non-2xx response. It matches ErrBadRequest,
+// ErrNotFound or ErrUnavailable with errors.Is according to its status.
+type Error struct {
+	Service    string
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s %s: %d %s", e.Service, e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnavailable:
+		return retryable(e.StatusCode)
+	}
+	return false
+}
+
+// decodeError reads the message from the services' {"error": "..."} body,
+// falling back to the raw body and then to the status text.
+func (c *Client) decodeError(method, path string, resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	message := strings.TrimSpace(string(raw))
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(raw, &body) == nil && body.Error != "" {
+		message = body.Error
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &Error{Service: c.service, Method: method, Path: path, StatusCode: resp.StatusCode, Message: message}
+}
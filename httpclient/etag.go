@@ -0,0 +1,22 @@
+package httpclient
+
+import "strings"
+
+// MatchETag reports whether an If-None-Match header value matches etag,
+// using the weak comparison required for conditional GETs. Services answer
+// conditional requests with it, and the gateway's response cache uses it
+// when serving stored responses.
+func MatchETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package httpclient
+
+import "testing"
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{"", `"a"`, false},
+		{`"a"`, "", false},
+		{`"a"`, `"a"`, true},
+		{`"b"`, `"a"`, false},
+		{` "b" , "a"`, `"a"`, true},
+		{`W/"a"`, `"a"`, true},
+		{`"a"`, `W/"a"`, true},
+		{"*", `"a"`, true},
+		{" * ", `"a"`, true},
+		{"a", `"a"`, false},
+	}
+	for _, tt := range tests {
+		if got := MatchETag(tt.ifNoneMatch, tt.etag); got != tt.want {
+			t.Errorf("MatchETag(%q, %q) = %v, want %v", tt.ifNoneMatch, tt.etag, got, tt.want)
+		}
+	}
+}
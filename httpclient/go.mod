@@ -0,0 +1,3 @@
+module github.com/rrxshxd/assignment1_advProg2/httpclient
+
+go 1.23.4
@@ -0,0 +1,161 @@
+// Package app assembles the inventory service from its configuration. The
+// binary in cmd serves it on real listeners; end-to-end tests serve it
+// in-process.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	sharedconfig "github.com/rrxshxd/assignment1_advProg2/config"
+	"github.com/rrxshxd/assignment1_advProg2/database"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/config"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/controller"
+	grpccontroller "github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/controller/grpc"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/metrics"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/openapi"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository/memory"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository/postgres"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/usecase"
+	"github.com/rrxshxd/assignment1_advProg2/observability/health"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	sharedopenapi "github.com/rrxshxd/assignment1_advProg2/openapi"
+	"github.com/rrxshxd/assignment1_advProg2/proto/inventory"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"log/slog"
+	"net/http"
+)
+
+type Config = config.Config
+
+// DefaultConfig returns a Config holding only the defaults, for callers that
+// build their configuration in code rather than loading it.
+func DefaultConfig() (*Config, error) {
+	cfg := &Config{}
+	if err := sharedconfig.Defaults(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// App is a wired inventory service. Neither server is bound to a listener;
+// that is up to the caller.
+type App struct {
+	HTTP http.Handler
+	GRPC *grpc.Server
+
+	db *database.DB
+}
+
+// New wires the service, connecting to the database unless cfg selects
+// in-memory storage.
+func New(ctx context.Context, cfg *Config, logger *slog.Logger) (*App, error) {
+	spec, err := openapi.Spec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	validator, err := sharedopenapi.Validator(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request validator: %w", err)
+	}
+
+	app := &App{}
+
+	var productRepo repository.ProductRepository
+	healthChecks := make(map[string]health.Check)
+	switch cfg.Storage {
+	case config.StorageMemory:
+		logger.Warn("Using in-memory storage, data is lost on restart")
+		productRepo = memory.NewProductRepository()
+	default:
+		db, err := openDatabase(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		app.db = db
+		productRepo = postgres.NewProductRepository(db)
+		healthChecks["database"] = db.PingContext
+	}
+
+	productUseCase := usecase.NewProductUseCase(productRepo)
+	inventoryController := controller.NewInventoryController(productUseCase, cfg.CacheMaxAge)
+	healthController := health.NewController(healthChecks)
+
+	app.GRPC = grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logging.UnaryServerInterceptor(logger)),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), logging.StreamServerInterceptor(logger)),
+	)
+	inventory.RegisterInventoryServiceServer(app.GRPC, grpccontroller.NewInventoryServer(productUseCase))
+	// The order service checks this before reporting itself ready.
+	grpc_health_v1.RegisterHealthServer(app.GRPC, grpchealth.NewServer())
+	reflection.Register(app.GRPC)
+
+	router := gin.New()
+	router.Use(
+		otelgin.Middleware("inventory_service"),
+		logging.HTTPMiddleware(logger),
+		logging.Recovery(),
+		metrics.HTTPMiddleware(),
+		validator,
+	)
+
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/openapi.json", sharedopenapi.Handler(spec))
+	router.GET("/docs", sharedopenapi.UI(spec.Info.Title, "/openapi.json"))
+
+	router.POST("/products/create", inventoryController.CreateProduct)
+	router.GET("/products/:id", inventoryController.GetProduct)
+	router.PATCH("/products/:id", inventoryController.UpdateProduct)
+	router.PUT("/products/:id", inventoryController.ReplaceProduct)
+	router.DELETE("/products/:id", inventoryController.DeleteProduct)
+	router.GET("/products", inventoryController.GetAll)
+	app.HTTP = router
+
+	return app, nil
+}
+
+// Close releases the database connections. Stop both servers first.
+func (a *App) Close() error {
+	if a.db == nil {
+		return nil
+	}
+	return a.db.Close()
+}
+
+// openDatabase connects to the primary and, when configured, the read
+// replica, and exports both pools' statistics.
+func openDatabase(ctx context.Context, cfg *Config) (*database.DB, error) {
+	pool := database.PoolConfig{
+		MaxOpenConns:     cfg.DBMaxOpenConns,
+		MaxIdleConns:     cfg.DBMaxIdleConns,
+		ConnMaxLifetime:  cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.DBConnMaxIdleTime,
+		StatementTimeout: cfg.DBStatementTimeout,
+	}
+	primary, err := database.Connect(ctx, cfg.DatabaseURL, pool, cfg.DBConnectAttempts, cfg.DBConnectRetryDelay)
+	if err != nil {
+		return nil, err
+	}
+	metrics.RegisterDB(primary, "products")
+
+	var replica *sql.DB
+	if cfg.DatabaseReplicaURL != "" {
+		replica, err = database.OpenReplica(ctx, cfg.DatabaseReplicaURL, pool)
+		if err != nil {
+			primary.Close()
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+		metrics.RegisterDB(replica, "products_replica")
+	}
+	return database.NewDB(primary, replica, metrics.ReplicaFallbacks.Inc), nil
+}
@@ -0,0 +1,61 @@
+// Package client is the Go SDK for the inventory service's HTTP API.
+//
+//	inventory := client.New("http://inventory:8081")
+//	product, err := inventory.GetProduct(ctx, 42)
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+//
+// Requests that are safe to repeat are retried on network errors and on 429,
+// 502, 503 and 504 responses, backing off exponentially between attempts.
+package client
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/httpclient"
+	"net/http"
+	"time"
+)
+
+type Client struct {
+	transport *httpclient.Client
+}
+
+type Option = httpclient.Option
+
+// WithHTTPClient replaces the default client, which has a 10s timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return httpclient.WithHTTPClient(httpClient)
+}
+
+// WithRetries sets how many times a failed idempotent request is repeated
+// and the delay before the first repeat, which doubles on each attempt.
+// Zero retries disables retrying.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return httpclient.WithRetries(retries, backoff)
+}
+
+// WithHeader sets a header on every request, replacing an earlier value.
+func WithHeader(name, value string) Option {
+	return httpclient.WithHeader(name, value)
+}
+
+// WithBearerToken authenticates every request with an access token.
+func WithBearerToken(token string) Option {
+	return httpclient.WithBearerToken(token)
+}
+
+// WithAPIKey authenticates every request with an API key.
+func WithAPIKey(key string) Option {
+	return httpclient.WithAPIKey(key)
+}
+
+// New returns a client for the inventory service at baseURL, for example
+// "http://localhost:8081".
+func New(baseURL string, opts ...Option) *Client {
+	return &Client{transport: httpclient.New("inventory", baseURL, opts...)}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	return c.transport.Do(ctx, method, path, body, out)
+}
@@ -0,0 +1,13 @@
+package client
+
+import "github.com/rrxshxd/assignment1_advProg2/httpclient"
+
+var (
+	ErrBadRequest  = httpclient.ErrBadRequest
+	ErrNotFound    = httpclient.ErrNotFound
+	ErrUnavailable = httpclient.ErrUnavailable
+)
+
+// Error is returned for every non-2xx response. It matches ErrBadRequest,
+// ErrNotFound or ErrUnavailable with errors.Is according to its status.
+type Error = httpclient.Error
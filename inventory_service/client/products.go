@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/dto"
+	"iter"
+	"net/http"
+)
+
+// maxPageSize is the largest page the server returns.
+const maxPageSize = 100
+
+func (c *Client) CreateProduct(ctx context.Context, request dto.CreateProductRequest) (*dto.Product, error) {
+	var product dto.Product
+	if err := c.do(ctx, http.MethodPost, "/products/create", request, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (c *Client) GetProduct(ctx context.Context, id uint) (*dto.Product, error) {
+	var product dto.Product
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/products/%d", id), nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// UpdateProduct changes the fields set in request and leaves the rest as they
+// are.
+func (c *Client) UpdateProduct(ctx context.Context, id uint, request dto.UpdateProductRequest) (*dto.Product, error) {
+	var product dto.Product
+	if err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/products/%d", id), request, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ReplaceProduct overwrites every field of a product.
+func (c *Client) ReplaceProduct(ctx context.Context, id uint, request dto.ReplaceProductRequest) (*dto.Product, error) {
+	var product dto.Product
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/products/%d", id), request, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (c *Client) DeleteProduct(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/products/%d", id), nil, nil)
+}
+
+// ListProducts returns one page of the products matching query.
+func (c *Client) ListProducts(ctx context.Context, query dto.ProductQuery) (*dto.ProductList, error) {
+	path := "/products"
+	if values := query.Values(); len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	var list dto.ProductList
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// Products iterates over every product matching query, fetching pages as
+// needed, starting from query.Page. A zero query.Limit fetches the largest
+// pages the server allows. Iteration stops after the first error.
+//
+//	for product, err := range inventory.Products(ctx, dto.ProductQuery{Category: "books"}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) Products(ctx context.Context, query dto.ProductQuery) iter.Seq2[dto.Product, error] {
+	return func(yield func(dto.Product, error) bool) {
+		if query.Page < 1 {
+			query.Page = 1
+		}
+		if query.Limit < 1 || query.Limit > maxPageSize {
+			query.Limit = maxPageSize
+		}
+
+		for {
+			list, err := c.ListProducts(ctx, query)
+			if err != nil {
+				yield(dto.Product{}, err)
+				return
+			}
+			for _, product := range list.Data {
+				if !yield(product, nil) {
+					return
+				}
+			}
+			if len(list.Data) < query.Limit {
+				return
+			}
+			query.Page++
+		}
+	}
+}
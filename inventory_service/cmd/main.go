@@ -1,38 +1,108 @@
 package main
 
 import (
-	"database/sql"
-	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/app"
 	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/config"
-	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/controller"
-	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository/postgres"
-	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/usecase"
-	"log"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"github.com/rrxshxd/assignment1_advProg2/observability/tracing"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
+	if err != nil {
+		slog.Error("Invalid log level", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	shutdownTracing, err := tracing.Setup(ctx, "inventory_service", tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+		StdoutFile:   cfg.TracingFile,
+	})
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "Failed to set up tracing", err)
 	}
-	defer db.Close()
 
-	productRepo := postgres.NewProductRepository(db)
-	productUseCase := usecase.NewProductUseCase(productRepo)
-	inventoryController := controller.NewInventoryController(productUseCase)
+	application, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		fatal(logger, "Failed to start inventory service", err)
+	}
+	defer application.Close()
+
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		fatal(logger, "Failed to listen", err)
+	}
 
-	router := gin.Default()
+	go func() {
+		logger.Info("Inventory gRPC server is running", "port", cfg.GRPCPort)
+		if err := application.GRPC.Serve(lis); err != nil {
+			fatal(logger, "Failed to serve gRPC", err)
+		}
+	}()
+
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: application.HTTP}
+	go func() {
+		logger.Info("Inventory HTTP server is running", "port", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal(logger, "Failed to serve HTTP", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("Shutting down, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown", "error", err)
+	}
+	gracefulStop(shutdownCtx, application.GRPC)
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Tracing shutdown", "error", err)
+	}
+}
+
+// fatal logs err and exits. Deferred calls do not run.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-	router.POST("/products/create", inventoryController.CreateProduct)
-	router.GET("/products/:id", inventoryController.GetProduct)
-	router.PATCH("/products/:id", inventoryController.UpdateProduct)
-	router.DELETE("/products/:id", inventoryController.DeleteProduct)
-	router.GET("/products", inventoryController.GetAll)
+// gracefulStop drains in-flight RPCs, forcing the server closed if ctx
+// expires first.
+func gracefulStop(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
 
-	if err := router.Run(":" + cfg.Port); err != nil {
-		log.Fatal(err)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
 	}
 }
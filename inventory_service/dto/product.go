@@ -0,0 +1,97 @@
+// Package dto defines the JSON bodies and query parameters of the inventory
+// service's HTTP API. The handlers and the client package share these types so
+// the two cannot drift apart.
+package dto
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Product struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Price       float64   `json:"price"`
+	Stock       int       `json:"stock"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CreateProductRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Category    string  `json:"category" binding:"required"`
+	Price       float64 `json:"price" binding:"required,gt=0"`
+	Stock       int     `json:"stock" binding:"gte=0"`
+}
+
+// UpdateProductRequest is a partial update with pointer fields: fields that
+// are absent keep their current value, so a description can be cleared by
+// sending an empty string and stock is only changed when it is sent. Null is
+// not a valid value for any field.
+type UpdateProductRequest struct {
+	Name        *string  `json:"name,omitempty" binding:"omitempty,min=1"`
+	Description *string  `json:"description,omitempty"`
+	Category    *string  `json:"category,omitempty" binding:"omitempty,min=1"`
+	Price       *float64 `json:"price,omitempty" binding:"omitempty,gt=0"`
+	Stock       *int     `json:"stock,omitempty" binding:"omitempty,gte=0"`
+}
+
+// ReplaceProductRequest replaces every field of a product. Stock must be sent,
+// even when it is zero, and an absent description clears it.
+type ReplaceProductRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Category    string  `json:"category" binding:"required"`
+	Price       float64 `json:"price" binding:"required,gt=0"`
+	Stock       *int    `json:"stock" binding:"required,gte=0"`
+}
+
+// ProductQuery holds the filters and page of GET /products. Zero values are
+// not sent; the server defaults to page 1 with 10 products.
+type ProductQuery struct {
+	Category string   `form:"category"`
+	Name     string   `form:"name"`
+	MinPrice *float64 `form:"min_price"`
+	MaxPrice *float64 `form:"max_price"`
+	Page     int      `form:"page" binding:"omitempty,gte=1"`
+	Limit    int      `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+// Values encodes q as URL query parameters.
+func (q ProductQuery) Values() url.Values {
+	values := url.Values{}
+	if q.Category != "" {
+		values.Set("category", q.Category)
+	}
+	if q.Name != "" {
+		values.Set("name", q.Name)
+	}
+	if q.MinPrice != nil {
+		values.Set("min_price", strconv.FormatFloat(*q.MinPrice, 'f', -1, 64))
+	}
+	if q.MaxPrice != nil {
+		values.Set("max_price", strconv.FormatFloat(*q.MaxPrice, 'f', -1, 64))
+	}
+	if q.Page > 0 {
+		values.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	return values
+}
+
+type ProductList struct {
+	Data  []Product `json:"data"`
+	Page  int       `json:"page"`
+	Limit int       `json:"limit"`
+}
+
+// Error is the body of every non-2xx response.
+type Error struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/httpclient"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"net/http"
+	"time"
+)
+
+// productsETag derives a validator from the identity and modification time of
+// every product in a representation, so any update or deletion changes it.
+func productsETag(extra string, products ...*entity.Product) string {
+	hash := sha256.New()
+	hash.Write([]byte(extra))
+	for _, product := range products {
+		fmt.Fprintf(hash, "|%d:%d", product.ID, product.UpdatedAt.UnixNano())
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
+
+func lastModified(products ...*entity.Product) time.Time {
+	var latest time.Time
+	for _, product := range products {
+		if product.UpdatedAt.After(latest) {
+			latest = product.UpdatedAt
+		}
+	}
+	return latest
+}
+
+// writeValidators sets caching headers and reports whether the client's
+// conditional request matched, in which case a 304 has already been sent.
+func (c *InventoryController) writeValidators(ctx *gin.Context, etag string, modified time.Time) bool {
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(c.cacheMaxAge.Seconds())))
+	if !modified.IsZero() {
+		ctx.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if httpclient.MatchETag(ifNoneMatch, etag) {
+			ctx.Status(http.StatusNotModified)
+			return true
+		}
+		return false
+	}
+
+	if ifModifiedSince := ctx.GetHeader("If-Modified-Since"); ifModifiedSince != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !modified.Truncate(time.Second).After(since) {
+			ctx.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"testing"
+	"time"
+)
+
+func TestProductsETag(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	product := &entity.Product{ID: 1, UpdatedAt: updated}
+	etag := productsETag("", product)
+
+	if len(etag) != 34 || etag[0] != '"' || etag[33] != '"' {
+		t.Fatalf("productsETag = %s, want a quoted 32-character tag", etag)
+	}
+	if again := productsETag("", &entity.Product{ID: 1, UpdatedAt: updated, Name: "renamed"}); again != etag {
+		t.Error("productsETag depends on fields other than the ID and modification time")
+	}
+
+	changed := map[string]string{
+		"modified": productsETag("", &entity.Product{ID: 1, UpdatedAt: updated.Add(time.Nanosecond)}),
+		"other id": productsETag("", &entity.Product{ID: 2, UpdatedAt: updated}),
+		"page":     productsETag("2/10", product),
+		"added":    productsETag("", product, &entity.Product{ID: 2, UpdatedAt: updated}),
+		"empty":    productsETag(""),
+	}
+	for name, other := range changed {
+		if other == etag {
+			t.Errorf("productsETag unchanged for %s representation", name)
+		}
+	}
+}
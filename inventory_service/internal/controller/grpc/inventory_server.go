@@ -0,0 +1,155 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/usecase"
+	"github.com/rrxshxd/assignment1_advProg2/proto/inventory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type InventoryServer struct {
+	inventory.UnimplementedInventoryServiceServer
+	productUseCase *usecase.ProductUseCase
+}
+
+func NewInventoryServer(productUseCase *usecase.ProductUseCase) *InventoryServer {
+	return &InventoryServer{productUseCase: productUseCase}
+}
+
+func (s *InventoryServer) GetProduct(ctx context.Context, req *inventory.GetProductRequest) (*inventory.Product, error) {
+	if req.Id == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	product, err := s.productUseCase.GetProduct(ctx, uint(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return productToProto(product), nil
+}
+
+func (s *InventoryServer) ListProducts(ctx context.Context, req *inventory.ListProductsRequest) (*inventory.ListProductsResponse, error) {
+	filters := make(map[string]interface{})
+	if req.Category != "" {
+		filters["category"] = req.Category
+	}
+	if req.MinPrice != nil {
+		filters["min_price"] = req.GetMinPrice()
+	}
+	if req.MaxPrice != nil {
+		filters["max_price"] = req.GetMaxPrice()
+	}
+	if req.Name != "" {
+		filters["name"] = req.Name
+	}
+
+	page, limit := int(req.Page), int(req.Limit)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	products, err := s.productUseCase.GetAll(ctx, page, limit, filters)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &inventory.ListProductsResponse{
+		Products: productsToProto(products),
+		Page:     int32(page),
+		Limit:    int32(limit),
+	}, nil
+}
+
+func (s *InventoryServer) BatchGetProducts(ctx context.Context, req *inventory.BatchGetProductsRequest) (*inventory.BatchGetProductsResponse, error) {
+	ids := make([]uint, len(req.Ids))
+	for i, id := range req.Ids {
+		ids[i] = uint(id)
+	}
+
+	products, missing, err := s.productUseCase.GetProducts(ctx, ids)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	missingIDs := make([]uint64, len(missing))
+	for i, id := range missing {
+		missingIDs[i] = uint64(id)
+	}
+
+	return &inventory.BatchGetProductsResponse{
+		Products:   productsToProto(products),
+		MissingIds: missingIDs,
+	}, nil
+}
+
+func (s *InventoryServer) ReserveStock(ctx context.Context, req *inventory.ReserveStockRequest) (*inventory.ReserveStockResponse, error) {
+	products, err := s.productUseCase.ReserveStock(ctx, stockItemsFromProto(req.Items))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &inventory.ReserveStockResponse{Products: productsToProto(products)}, nil
+}
+
+func (s *InventoryServer) ReleaseStock(ctx context.Context, req *inventory.ReleaseStockRequest) (*inventory.ReleaseStockResponse, error) {
+	products, err := s.productUseCase.ReleaseStock(ctx, stockItemsFromProto(req.Items))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &inventory.ReleaseStockResponse{Products: productsToProto(products)}, nil
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrInsufficientStock), errors.Is(err, repository.ErrExcessRelease):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrInvalidStockRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func stockItemsFromProto(items []*inventory.StockItem) []entity.StockItem {
+	result := make([]entity.StockItem, len(items))
+	for i, item := range items {
+		result[i] = entity.StockItem{
+			ProductID: uint(item.ProductId),
+			Quantity:  int(item.Quantity),
+		}
+	}
+	return result
+}
+
+func productsToProto(products []*entity.Product) []*inventory.Product {
+	result := make([]*inventory.Product, len(products))
+	for i, product := range products {
+		result[i] = productToProto(product)
+	}
+	return result
+}
+
+func productToProto(product *entity.Product) *inventory.Product {
+	return &inventory.Product{
+		Id:          uint64(product.ID),
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		Stock:       int32(product.Stock),
+		CreatedAt:   timestamppb.New(product.CreatedAt),
+		UpdatedAt:   timestamppb.New(product.UpdatedAt),
+	}
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository/memory"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/usecase"
+	"github.com/rrxshxd/assignment1_advProg2/proto/inventory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+// newTestServer serves two products with 5 in stock, 2 of the first one
+// already reserved.
+func newTestServer(t *testing.T) (*InventoryServer, *usecase.ProductUseCase) {
+	t.Helper()
+	ctx := context.Background()
+	productUseCase := usecase.NewProductUseCase(memory.NewProductRepository())
+	for _, name := range []string{"Keyboard", "Mouse"} {
+		if err := productUseCase.CreateProduct(ctx, &entity.Product{Name: name, Category: "peripherals", Price: 10, Stock: 5}); err != nil {
+			t.Fatalf("CreateProduct: %v", err)
+		}
+	}
+	if _, err := productUseCase.ReserveStock(ctx, []entity.StockItem{{ProductID: 1, Quantity: 2}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	return NewInventoryServer(productUseCase), productUseCase
+}
+
+func items(pairs ...uint64) []*inventory.StockItem {
+	result := make([]*inventory.StockItem, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result = append(result, &inventory.StockItem{ProductId: pairs[i], Quantity: int32(pairs[i+1])})
+	}
+	return result
+}
+
+func TestStockRPCs(t *testing.T) {
+	reserve := func(s *InventoryServer, items []*inventory.StockItem) ([]*inventory.Product, error) {
+		resp, err := s.ReserveStock(context.Background(), &inventory.ReserveStockRequest{Items: items})
+		return resp.GetProducts(), err
+	}
+	release := func(s *InventoryServer, items []*inventory.StockItem) ([]*inventory.Product, error) {
+		resp, err := s.ReleaseStock(context.Background(), &inventory.ReleaseStockRequest{Items: items})
+		return resp.GetProducts(), err
+	}
+
+	tests := []struct {
+		name      string
+		call      func(*InventoryServer, []*inventory.StockItem) ([]*inventory.Product, error)
+		items     []*inventory.StockItem
+		wantCode  codes.Code
+		wantStock map[uint]int
+	}{
+		{name: "reserve", call: reserve, items: items(1, 3, 2, 5), wantCode: codes.OK, wantStock: map[uint]int{1: 0, 2: 0}},
+		{name: "reserve repeated product", call: reserve, items: items(1, 2, 1, 1), wantCode: codes.OK, wantStock: map[uint]int{1: 0}},
+		{name: "reserve more than in stock", call: reserve, items: items(2, 1, 1, 4), wantCode: codes.FailedPrecondition, wantStock: map[uint]int{1: 3, 2: 5}},
+		{name: "reserve unknown product", call: reserve, items: items(1, 1, 404, 1), wantCode: codes.NotFound, wantStock: map[uint]int{1: 3}},
+		{name: "reserve nothing", call: reserve, items: nil, wantCode: codes.InvalidArgument},
+		{name: "reserve zero", call: reserve, items: items(1, 0), wantCode: codes.InvalidArgument, wantStock: map[uint]int{1: 3}},
+		{name: "release", call: release, items: items(1, 2), wantCode: codes.OK, wantStock: map[uint]int{1: 5}},
+		{name: "release more than reserved", call: release, items: items(1, 3), wantCode: codes.FailedPrecondition, wantStock: map[uint]int{1: 3}},
+		{name: "release unreserved product", call: release, items: items(1, 1, 2, 1), wantCode: codes.FailedPrecondition, wantStock: map[uint]int{1: 3, 2: 5}},
+		{name: "release unknown product", call: release, items: items(404, 1), wantCode: codes.NotFound},
+		{name: "release zero", call: release, items: items(1, 0), wantCode: codes.InvalidArgument, wantStock: map[uint]int{1: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, productUseCase := newTestServer(t)
+
+			products, err := tt.call(server, tt.items)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v (%v), want %v", code, err, tt.wantCode)
+			}
+			if err == nil && len(products) != len(tt.wantStock) {
+				t.Errorf("returned %d products, want %d", len(products), len(tt.wantStock))
+			}
+			for id, want := range tt.wantStock {
+				product, err := productUseCase.GetProduct(context.Background(), id)
+				if err != nil {
+					t.Fatalf("GetProduct(%d): %v", id, err)
+				}
+				if product.Stock != want {
+					t.Errorf("stock of product %d = %d, want %d", id, product.Stock, want)
+				}
+			}
+		})
+	}
+}
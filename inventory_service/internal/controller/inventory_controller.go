@@ -1,33 +1,32 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/dto"
 	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
 	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/usecase"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type InventoryController struct {
 	productUseCase *usecase.ProductUseCase
+	cacheMaxAge    time.Duration
 }
 
-func NewInventoryController(productUseCase *usecase.ProductUseCase) *InventoryController {
-	return &InventoryController{productUseCase: productUseCase}
+func NewInventoryController(productUseCase *usecase.ProductUseCase, cacheMaxAge time.Duration) *InventoryController {
+	return &InventoryController{productUseCase: productUseCase, cacheMaxAge: cacheMaxAge}
 }
 
 func (c *InventoryController) CreateProduct(ctx *gin.Context) {
-	var request struct {
-		Name        string  `json:"name" binding:"required"`
-		Description string  `json:"description"`
-		Category    string  `json:"category" binding:"required"`
-		Price       float64 `json:"price" binding:"required,gt=0"`
-		Stock       int     `json:"stock" binding:"gte=0"`
-	}
+	var request dto.CreateProductRequest
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
 		return
 	}
 
@@ -39,124 +38,100 @@ func (c *InventoryController) CreateProduct(ctx *gin.Context) {
 		Stock:       request.Stock,
 	}
 
-	if err := c.productUseCase.CreateProduct(product); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.productUseCase.CreateProduct(ctx.Request.Context(), product); err != nil {
+		writeProductError(ctx, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"id":          product.ID,
-		"name":        product.Name,
-		"description": product.Description,
-		"category":    product.Category,
-		"price":       product.Price,
-		"stock":       product.Stock,
-		"created_at":  product.CreatedAt,
-	}
-
-	ctx.JSON(http.StatusCreated, response)
+	ctx.JSON(http.StatusCreated, productResponse(product))
 }
 
 func (c *InventoryController) GetProduct(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid product ID"})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: "invalid product ID"})
 		return
 	}
 
-	product, err := c.productUseCase.GetProduct(uint(id))
+	product, err := c.productUseCase.GetProduct(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		writeProductError(ctx, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"id":          product.ID,
-		"name":        product.Name,
-		"description": product.Description,
-		"category":    product.Category,
-		"price":       product.Price,
-		"stock":       product.Stock,
-		"created_at":  product.CreatedAt,
-		"updated_at":  product.UpdatedAt,
+	if c.writeValidators(ctx, productsETag("", product), product.UpdatedAt) {
+		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, productResponse(product))
 }
 
 func (c *InventoryController) UpdateProduct(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid product ID"})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: "invalid product ID"})
 		return
 	}
 
-	var request struct {
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		Category    string  `json:"category"`
-		Price       float64 `json:"price"`
-		Stock       int     `json:"stock"`
-	}
-
+	var request dto.UpdateProductRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
 		return
 	}
 
-	existingProduct, err := c.productUseCase.GetProduct(uint(id))
+	product, err := c.productUseCase.UpdateProduct(ctx.Request.Context(), uint(id), entity.ProductPatch{
+		Name:        request.Name,
+		Description: request.Description,
+		Category:    request.Category,
+		Price:       request.Price,
+		Stock:       request.Stock,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		writeProductError(ctx, err)
 		return
 	}
 
-	if request.Name != "" {
-		existingProduct.Name = request.Name
-	}
-	if request.Description != "" {
-		existingProduct.Description = request.Description
-	}
-	if request.Category != "" {
-		existingProduct.Category = request.Category
-	}
-	if request.Price > 0 {
-		existingProduct.Price = request.Price
-	}
-	if request.Stock >= 0 {
-		existingProduct.Stock = request.Stock
+	ctx.JSON(http.StatusOK, productResponse(product))
+}
+
+func (c *InventoryController) ReplaceProduct(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: "invalid product ID"})
+		return
 	}
 
-	if err := c.productUseCase.UpdateProduct(existingProduct); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var request dto.ReplaceProductRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
 		return
 	}
 
-	response := map[string]interface{}{
-		"id":          existingProduct.ID,
-		"name":        existingProduct.Name,
-		"description": existingProduct.Description,
-		"category":    existingProduct.Category,
-		"price":       existingProduct.Price,
-		"stock":       existingProduct.Stock,
-		"updated_at":  existingProduct.UpdatedAt,
+	product := &entity.Product{
+		ID:          uint(id),
+		Name:        request.Name,
+		Description: request.Description,
+		Category:    request.Category,
+		Price:       request.Price,
+		Stock:       *request.Stock,
+	}
+	if err := c.productUseCase.ReplaceProduct(ctx.Request.Context(), product); err != nil {
+		writeProductError(ctx, err)
+		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, productResponse(product))
 }
 
 func (c *InventoryController) DeleteProduct(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid product ID"})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: "invalid product ID"})
 		return
 	}
 
-	if err := c.productUseCase.DeleteProduct(uint(id)); err != nil {
-		if err == fmt.Errorf("Product with such id can't be found: %d", id) {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.productUseCase.DeleteProduct(ctx.Request.Context(), uint(id)); err != nil {
+		writeProductError(ctx, err)
 		return
 	}
 
@@ -164,49 +139,65 @@ func (c *InventoryController) DeleteProduct(ctx *gin.Context) {
 }
 
 func (c *InventoryController) GetAll(ctx *gin.Context) {
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	query := dto.ProductQuery{Page: 1, Limit: 10}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+	page, limit := query.Page, query.Limit
 
 	filters := make(map[string]interface{})
-	if category := ctx.Query("category"); category != "" {
-		filters["category"] = category
+	if query.Category != "" {
+		filters["category"] = query.Category
 	}
-	if minPrice := ctx.Query("min_price"); minPrice != "" {
-		if min, err := strconv.ParseFloat(minPrice, 64); err == nil {
-			filters["min_price"] = min
-		}
+	if query.MinPrice != nil {
+		filters["min_price"] = *query.MinPrice
 	}
-	if maxPrice := ctx.Query("max_price"); maxPrice != "" {
-		if max, err := strconv.ParseFloat(maxPrice, 64); err == nil {
-			filters["max_price"] = max
-		}
+	if query.MaxPrice != nil {
+		filters["max_price"] = *query.MaxPrice
 	}
-	if name := ctx.Query("name"); name != "" {
-		filters["name"] = name
+	if query.Name != "" {
+		filters["name"] = query.Name
 	}
 
-	products, err := c.productUseCase.GetAll(page, limit, filters)
+	products, err := c.productUseCase.GetAll(ctx.Request.Context(), page, limit, filters)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
 		return
 	}
 
-	response := make([]map[string]interface{}, 0, len(products))
+	if c.writeValidators(ctx, productsETag(fmt.Sprintf("%d/%d", page, limit), products...), lastModified(products...)) {
+		return
+	}
+
+	response := dto.ProductList{Data: make([]dto.Product, 0, len(products)), Page: page, Limit: limit}
 	for _, product := range products {
-		response = append(response, map[string]interface{}{
-			"id":          product.ID,
-			"name":        product.Name,
-			"description": product.Description,
-			"category":    product.Category,
-			"price":       product.Price,
-			"stock":       product.Stock,
-			"created_at":  product.CreatedAt,
-		})
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"data":  response,
-		"page":  page,
-		"limit": limit,
-	})
+		response.Data = append(response.Data, productResponse(product))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func writeProductError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidProduct):
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+	case errors.Is(err, repository.ErrProductNotFound):
+		ctx.JSON(http.StatusNotFound, dto.Error{Error: "product not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+	}
+}
+
+func productResponse(product *entity.Product) dto.Product {
+	return dto.Product{
+		ID:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+	}
 }
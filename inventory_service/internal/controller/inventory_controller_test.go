@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingRepository fails every single-product call with err.
+type failingRepository struct {
+	repository.ProductRepository
+	err error
+}
+
+func (r failingRepository) FindByID(context.Context, uint) (*entity.Product, error) {
+	return nil, r.err
+}
+
+func (r failingRepository) Patch(context.Context, uint, entity.ProductPatch) (*entity.Product, error) {
+	return nil, r.err
+}
+
+func (r failingRepository) Update(context.Context, *entity.Product) error {
+	return r.err
+}
+
+func (r failingRepository) Delete(context.Context, uint) error {
+	return r.err
+}
+
+func TestProductErrorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	notFound := fmt.Errorf("%w: 7", repository.ErrProductNotFound)
+	unavailable := errors.New("connection refused")
+
+	requests := []struct {
+		method string
+		body   string
+	}{
+		{http.MethodGet, ""},
+		{http.MethodPatch, `{"name":"Desk"}`},
+		{http.MethodPut, `{"name":"Desk","category":"furniture","price":10,"stock":1}`},
+		{http.MethodDelete, ""},
+	}
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "not found", err: notFound, wantCode: http.StatusNotFound},
+		{name: "repository failure", err: unavailable, wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		for _, req := range requests {
+			t.Run(tt.name+"/"+req.method, func(t *testing.T) {
+				c := NewInventoryController(usecase.NewProductUseCase(failingRepository{err: tt.err}), time.Minute)
+				router := gin.New()
+				router.GET("/products/:id", c.GetProduct)
+				router.PATCH("/products/:id", c.UpdateProduct)
+				router.PUT("/products/:id", c.ReplaceProduct)
+				router.DELETE("/products/:id", c.DeleteProduct)
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(req.method, "/products/7", strings.NewReader(req.body)))
+
+				if rec.Code != tt.wantCode {
+					t.Errorf("status = %d %s, want %d", rec.Code, rec.Body, tt.wantCode)
+				}
+			})
+		}
+	}
+}
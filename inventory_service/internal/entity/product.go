@@ -12,3 +12,31 @@ type Product struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// ProductPatch holds the fields of a partial update; nil fields are left
+// unchanged.
+type ProductPatch struct {
+	Name        *string
+	Description *string
+	Category    *string
+	Price       *float64
+	Stock       *int
+}
+
+func (p ProductPatch) Apply(product *Product) {
+	if p.Name != nil {
+		product.Name = *p.Name
+	}
+	if p.Description != nil {
+		product.Description = *p.Description
+	}
+	if p.Category != nil {
+		product.Category = *p.Category
+	}
+	if p.Price != nil {
+		product.Price = *p.Price
+	}
+	if p.Stock != nil {
+		product.Stock = *p.Stock
+	}
+}
@@ -0,0 +1,6 @@
+package entity
+
+type StockItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	sharedmetrics "github.com/rrxshxd/assignment1_advProg2/observability/metrics"
+	"google.golang.org/grpc"
+	"net/http"
+)
+
+// registry holds this service's metrics instead of the global default, so
+// several services can share a process without their metric names clashing.
+var registry = sharedmetrics.NewRegistry()
+
+var factory = promauto.With(registry)
+
+// StockOuts counts reservations rejected because a product ran out of stock.
+var StockOuts = factory.NewCounter(prometheus.CounterOpts{
+	Name: "inventory_stock_outs_total",
+	Help: "Stock reservations rejected for insufficient stock.",
+})
+
+// ReplicaFallbacks counts reads retried on the primary after the read replica
+// failed.
+var ReplicaFallbacks = factory.NewCounter(prometheus.CounterOpts{
+	Name: "db_replica_fallbacks_total",
+	Help: "Reads sent to the primary because the read replica failed.",
+})
+
+// RegisterDB exports the connection pool statistics of db. A pool registered
+// under the same name before, by an earlier instance of the service in this
+// process, is replaced.
+func RegisterDB(db *sql.DB, name string) {
+	sharedmetrics.RegisterDB(registry, db, name)
+}
+
+var httpMetrics = sharedmetrics.NewHTTP(registry)
+
+// HTTPMiddleware records rate, errors and duration per route.
+func HTTPMiddleware() gin.HandlerFunc {
+	return httpMetrics.Middleware()
+}
+
+var grpcMetrics = sharedmetrics.NewGRPC(registry)
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return grpcMetrics.UnaryServerInterceptor()
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return grpcMetrics.StreamServerInterceptor()
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
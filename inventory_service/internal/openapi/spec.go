@@ -0,0 +1,121 @@
+// Package openapi describes the inventory HTTP API as an OpenAPI 3 document
+// generated from the dto types.
+package openapi
+
+import (
+	"context"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/dto"
+	sharedopenapi "github.com/rrxshxd/assignment1_advProg2/openapi"
+	"net/http"
+)
+
+// Spec returns the validated OpenAPI document of the inventory HTTP API.
+func Spec(ctx context.Context) (*openapi3.T, error) {
+	schemas, err := sharedopenapi.GenerateSchemas(map[string]any{
+		"Product":               dto.Product{},
+		"CreateProductRequest":  dto.CreateProductRequest{},
+		"UpdateProductRequest":  dto.UpdateProductRequest{},
+		"ReplaceProductRequest": dto.ReplaceProductRequest{},
+		"ProductList":           dto.ProductList{},
+		"Error":                 dto.Error{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	listParameters, err := sharedopenapi.QueryParameters(dto.ProductQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	productID := sharedopenapi.IDParameter("Product ID")
+	invalid := sharedopenapi.JSONResponse("Invalid request", "Error")
+	notFound := sharedopenapi.JSONResponse("Product not found", "Error")
+	failed := sharedopenapi.JSONResponse("Internal error", "Error")
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:   "Inventory service",
+			Version: "1.0.0",
+		},
+		Components: &openapi3.Components{Schemas: schemas},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/products", &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					OperationID: "listProducts",
+					Summary:     "List products matching the filters, one page at a time",
+					Parameters:  listParameters,
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusOK:                  sharedopenapi.JSONResponse("A page of products", "ProductList"),
+						http.StatusNotModified:         sharedopenapi.EmptyResponse("The page matches the client's ETag"),
+						http.StatusBadRequest:          invalid,
+						http.StatusInternalServerError: failed,
+					}),
+				},
+			}),
+			openapi3.WithPath("/products/create", &openapi3.PathItem{
+				Post: &openapi3.Operation{
+					OperationID: "createProduct",
+					Summary:     "Create a product",
+					RequestBody: sharedopenapi.JSONBody("CreateProductRequest"),
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusCreated:             sharedopenapi.JSONResponse("The created product", "Product"),
+						http.StatusBadRequest:          invalid,
+						http.StatusInternalServerError: failed,
+					}),
+				},
+			}),
+			openapi3.WithPath("/products/{id}", &openapi3.PathItem{
+				Parameters: openapi3.Parameters{productID},
+				Get: &openapi3.Operation{
+					OperationID: "getProduct",
+					Summary:     "Get a product",
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusOK:          sharedopenapi.JSONResponse("The product", "Product"),
+						http.StatusNotModified: sharedopenapi.EmptyResponse("The product matches the client's ETag"),
+						http.StatusBadRequest:  invalid,
+						http.StatusNotFound:    notFound,
+					}),
+				},
+				Patch: &openapi3.Operation{
+					OperationID: "updateProduct",
+					Summary:     "Change the fields present in the body, leaving the others as they are",
+					RequestBody: sharedopenapi.JSONBody("UpdateProductRequest"),
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusOK:                  sharedopenapi.JSONResponse("The updated product", "Product"),
+						http.StatusBadRequest:          invalid,
+						http.StatusNotFound:            notFound,
+						http.StatusInternalServerError: failed,
+					}),
+				},
+				Put: &openapi3.Operation{
+					OperationID: "replaceProduct",
+					Summary:     "Replace every field of a product",
+					RequestBody: sharedopenapi.JSONBody("ReplaceProductRequest"),
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusOK:                  sharedopenapi.JSONResponse("The replaced product", "Product"),
+						http.StatusBadRequest:          invalid,
+						http.StatusNotFound:            notFound,
+						http.StatusInternalServerError: failed,
+					}),
+				},
+				Delete: &openapi3.Operation{
+					OperationID: "deleteProduct",
+					Summary:     "Delete a product",
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusNoContent:           sharedopenapi.EmptyResponse("The product was deleted"),
+						http.StatusBadRequest:          invalid,
+						http.StatusNotFound:            notFound,
+						http.StatusInternalServerError: failed,
+					}),
+				},
+			}),
+		),
+	}
+
+	if err := sharedopenapi.Resolve(ctx, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
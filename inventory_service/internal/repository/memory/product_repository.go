@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type productRepository struct {
+	mu       sync.RWMutex
+	products map[uint]entity.Product
+	reserved map[uint]int
+	nextID   uint
+}
+
+// NewProductRepository returns an empty store that behaves like the Postgres
+// repository: IDs start at 1, FindAll and FindByIDs order by ID, and stock
+// changes apply to all items or none.
+func NewProductRepository() repository.ProductRepository {
+	return &productRepository{products: make(map[uint]entity.Product), reserved: make(map[uint]int), nextID: 1}
+}
+
+func (r *productRepository) Create(ctx context.Context, product *entity.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product.ID = r.nextID
+	r.nextID++
+
+	stored := *product
+	now := time.Now()
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	r.products[stored.ID] = stored
+
+	return nil
+}
+
+func (r *productRepository) FindByID(ctx context.Context, id uint) (*entity.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", repository.ErrProductNotFound, id)
+	}
+	return &product, nil
+}
+
+func (r *productRepository) Update(ctx context.Context, product *entity.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.products[product.ID]
+	if !ok {
+		return fmt.Errorf("%w: %d", repository.ErrProductNotFound, product.ID)
+	}
+
+	product.UpdatedAt = time.Now()
+	stored := *product
+	stored.CreatedAt = existing.CreatedAt
+	r.products[stored.ID] = stored
+
+	return nil
+}
+
+func (r *productRepository) Patch(ctx context.Context, id uint, patch entity.ProductPatch) (*entity.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", repository.ErrProductNotFound, id)
+	}
+
+	patch.Apply(&product)
+	product.UpdatedAt = time.Now()
+	r.products[id] = product
+
+	return &product, nil
+}
+
+func (r *productRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return fmt.Errorf("%w: %d", repository.ErrProductNotFound, id)
+	}
+	delete(r.products, id)
+	delete(r.reserved, id)
+
+	return nil
+}
+
+func (r *productRepository) FindAll(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*entity.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var namePattern *regexp.Regexp
+	if name, ok := filters["name"].(string); ok {
+		namePattern = ilike("%" + name + "%")
+	}
+
+	var products []*entity.Product
+	for _, product := range r.sorted() {
+		if category, ok := filters["category"]; ok && product.Category != category {
+			continue
+		}
+		if min, ok := filters["min_price"].(float64); ok && product.Price < min {
+			continue
+		}
+		if max, ok := filters["max_price"].(float64); ok && product.Price > max {
+			continue
+		}
+		if namePattern != nil && !namePattern.MatchString(product.Name) {
+			continue
+		}
+		products = append(products, product)
+	}
+
+	if page > 0 && limit > 0 {
+		offset := (page - 1) * limit
+		if offset >= len(products) {
+			return nil, nil
+		}
+		products = products[offset:]
+	}
+	if limit > 0 && len(products) > limit {
+		products = products[:limit]
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) FindByIDs(ctx context.Context, ids []uint) ([]*entity.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var products []*entity.Product
+	for _, product := range r.sorted() {
+		if wanted[product.ID] {
+			products = append(products, product)
+		}
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) ReserveStock(ctx context.Context, items []entity.StockItem) ([]*entity.Product, error) {
+	return r.adjustStock(items, func(product *entity.Product, reserved *int, quantity int) error {
+		if product.Stock < quantity {
+			return fmt.Errorf("%w for product %d", repository.ErrInsufficientStock, product.ID)
+		}
+		product.Stock -= quantity
+		*reserved += quantity
+		return nil
+	})
+}
+
+func (r *productRepository) ReleaseStock(ctx context.Context, items []entity.StockItem) ([]*entity.Product, error) {
+	return r.adjustStock(items, func(product *entity.Product, reserved *int, quantity int) error {
+		if *reserved < quantity {
+			return fmt.Errorf("%w for product %d", repository.ErrExcessRelease, product.ID)
+		}
+		product.Stock += quantity
+		*reserved -= quantity
+		return nil
+	})
+}
+
+// adjustStock applies change to working copies in ID order and only stores
+// them once every item has succeeded.
+func (r *productRepository) adjustStock(items []entity.StockItem, change func(product *entity.Product, reserved *int, quantity int) error) ([]*entity.Product, error) {
+	sorted := append([]entity.StockItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	working := make(map[uint]entity.Product, len(sorted))
+	reserved := make(map[uint]int, len(sorted))
+	products := make([]*entity.Product, 0, len(sorted))
+	now := time.Now()
+	for _, item := range sorted {
+		product, ok := working[item.ProductID]
+		if !ok {
+			if product, ok = r.products[item.ProductID]; !ok {
+				return nil, fmt.Errorf("%w: %d", repository.ErrProductNotFound, item.ProductID)
+			}
+			reserved[item.ProductID] = r.reserved[item.ProductID]
+		}
+		count := reserved[product.ID]
+		if err := change(&product, &count, item.Quantity); err != nil {
+			return nil, err
+		}
+		product.UpdatedAt = now
+		working[product.ID] = product
+		reserved[product.ID] = count
+
+		result := product
+		products = append(products, &result)
+	}
+
+	for id, product := range working {
+		r.products[id] = product
+		r.reserved[id] = reserved[id]
+	}
+
+	return products, nil
+}
+
+// sorted returns copies of all products in ID order. Callers hold r.mu.
+func (r *productRepository) sorted() []*entity.Product {
+	products := make([]*entity.Product, 0, len(r.products))
+	for _, product := range r.products {
+		product := product
+		products = append(products, &product)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products
+}
+
+// ilike compiles a SQL ILIKE pattern, where % matches any run of characters,
+// _ matches one character and a backslash escapes the next one.
+func ilike(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString(`(?is)^`)
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(`.*`)
+		case r == '_':
+			b.WriteString(`.`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.MustCompile(b.String())
+}
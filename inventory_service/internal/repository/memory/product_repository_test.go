@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestProductRepository(t *testing.T) {
+	repositorytest.TestProductRepository(t, func(*testing.T) repository.ProductRepository {
+		return NewProductRepository()
+	})
+}
@@ -1,36 +1,51 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
+	"github.com/rrxshxd/assignment1_advProg2/database"
 	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
 	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/observability/tracing"
+	"sort"
 	"strings"
 	"time"
 )
 
+var tracer = tracing.NewDBTracer("github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository/postgres")
+
+const productColumns = `id, name, description, category, price, stock, created_at, updated_at`
+
 type productRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
-func NewProductRepository(db *sql.DB) repository.ProductRepository {
+func NewProductRepository(db *database.DB) repository.ProductRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) Create(product *entity.Product) error {
+func (r *productRepository) Create(ctx context.Context, product *entity.Product) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.Create", "INSERT", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `INSERT INTO products (name, description, category, price, stock, created_at, updated_at)
 			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
-	return r.db.QueryRow(query, product.Name, product.Description, product.Category, product.Price, product.Stock, time.Now(), time.Now()).Scan(&product.ID)
+	return r.db.QueryRowContext(ctx, query, product.Name, product.Description, product.Category, product.Price, product.Stock, time.Now(), time.Now()).Scan(&product.ID)
 }
 
-func (r *productRepository) FindByID(id uint) (*entity.Product, error) {
+func (r *productRepository) FindByID(ctx context.Context, id uint) (_ *entity.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.FindByID", "SELECT", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `SELECT id, name, description, category, price, stock, created_at, updated_at 
 	          FROM products WHERE id = $1`
 
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	var product entity.Product
-	err := row.Scan(
+	err = row.Scan(
 		&product.ID,
 		&product.Name,
 		&product.Description,
@@ -43,7 +58,7 @@ func (r *productRepository) FindByID(id uint) (*entity.Product, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("Product can't be found: %v", err)
+			return nil, fmt.Errorf("%w: %d", repository.ErrProductNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to find product by ID: %w", err)
 	}
@@ -51,14 +66,18 @@ func (r *productRepository) FindByID(id uint) (*entity.Product, error) {
 	return &product, nil
 }
 
-func (r *productRepository) Update(product *entity.Product) error {
+func (r *productRepository) Update(ctx context.Context, product *entity.Product) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.Update", "UPDATE", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `UPDATE products 
 	          SET name = $1, description = $2, category = $3, 
 	              price = $4, stock = $5, updated_at = NOW() 
 	          WHERE id = $6
 	          RETURNING updated_at`
 
-	err := r.db.QueryRow(
+	err = r.db.QueryRowContext(
+		ctx,
 		query,
 		product.Name,
 		product.Description,
@@ -70,7 +89,7 @@ func (r *productRepository) Update(product *entity.Product) error {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("Product can't be found: %v", err)
+			return fmt.Errorf("%w: %d", repository.ErrProductNotFound, product.ID)
 		}
 		return fmt.Errorf("failed to update product: %w", err)
 	}
@@ -78,9 +97,43 @@ func (r *productRepository) Update(product *entity.Product) error {
 	return nil
 }
 
-func (r *productRepository) Delete(id uint) error {
+func (r *productRepository) Patch(ctx context.Context, id uint, patch entity.ProductPatch) (_ *entity.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.Patch", "UPDATE", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
+	query := `UPDATE products
+	          SET name = COALESCE($1, name), description = COALESCE($2, description),
+	              category = COALESCE($3, category), price = COALESCE($4, price),
+	              stock = COALESCE($5, stock), updated_at = NOW()
+	          WHERE id = $6
+	          RETURNING ` + productColumns
+
+	product, err := scanProduct(r.db.QueryRowContext(
+		ctx,
+		query,
+		patch.Name,
+		patch.Description,
+		patch.Category,
+		patch.Price,
+		patch.Stock,
+		id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %d", repository.ErrProductNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to patch product: %w", err)
+	}
+
+	return product, nil
+}
+
+func (r *productRepository) Delete(ctx context.Context, id uint) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.Delete", "DELETE", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `DELETE FROM products WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
@@ -91,13 +144,16 @@ func (r *productRepository) Delete(id uint) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("Product with such id can't be found: %d", id)
+		return fmt.Errorf("%w: %d", repository.ErrProductNotFound, id)
 	}
 
 	return nil
 }
 
-func (r *productRepository) FindAll(page, limit int, filters map[string]interface{}) ([]*entity.Product, error) {
+func (r *productRepository) FindAll(ctx context.Context, page, limit int, filters map[string]interface{}) (_ []*entity.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.FindAll", "SELECT", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	baseQuery := `SELECT id, name, description, category, price, stock, created_at, updated_at 
 	              FROM products`
 
@@ -129,6 +185,7 @@ func (r *productRepository) FindAll(page, limit int, filters map[string]interfac
 	if len(whereClauses) > 0 {
 		baseQuery += " WHERE " + strings.Join(whereClauses, " AND ")
 	}
+	baseQuery += " ORDER BY id"
 
 	if limit > 0 {
 		baseQuery += fmt.Sprintf(" LIMIT $%d", argPos)
@@ -142,34 +199,147 @@ func (r *productRepository) FindAll(page, limit int, filters map[string]interfac
 		args = append(args, offset)
 	}
 
-	rows, err := r.db.Query(baseQuery, args...)
+	var products []*entity.Product
+	err = r.db.Read(ctx, func(db *sql.DB) error {
+		products, err = queryProducts(ctx, db, baseQuery, args...)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find products: %w", err)
 	}
+
+	return products, nil
+}
+
+func (r *productRepository) FindByIDs(ctx context.Context, ids []uint) (_ []*entity.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.FindByIDs", "SELECT", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = ANY($1) ORDER BY id`
+
+	int64IDs := make([]int64, len(ids))
+	for i, id := range ids {
+		int64IDs[i] = int64(id)
+	}
+
+	var products []*entity.Product
+	err = r.db.Read(ctx, func(db *sql.DB) error {
+		products, err = queryProducts(ctx, db, query, pq.Array(int64IDs))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products by IDs: %w", err)
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) ReserveStock(ctx context.Context, items []entity.StockItem) (_ []*entity.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.ReserveStock", "UPDATE", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
+	query := `UPDATE products SET stock = stock - $1, reserved = reserved + $1, updated_at = NOW()
+	          WHERE id = $2 AND stock >= $1
+	          RETURNING ` + productColumns
+	return r.adjustStock(ctx, query, items, repository.ErrInsufficientStock)
+}
+
+func (r *productRepository) ReleaseStock(ctx context.Context, items []entity.StockItem) (_ []*entity.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.ReleaseStock", "UPDATE", "products")
+	defer func() { tracer.End(ctx, span, err) }()
+
+	query := `UPDATE products SET stock = stock + $1, reserved = reserved - $1, updated_at = NOW()
+	          WHERE id = $2 AND reserved >= $1
+	          RETURNING ` + productColumns
+	return r.adjustStock(ctx, query, items, repository.ErrExcessRelease)
+}
+
+// adjustStock applies query to every item in one transaction. Items are
+// locked in ID order so concurrent reservations cannot deadlock. shortfall is
+// reported for an existing product whose row the query's condition skipped.
+func (r *productRepository) adjustStock(ctx context.Context, query string, items []entity.StockItem, shortfall error) ([]*entity.Product, error) {
+	sorted := append([]entity.StockItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin stock transaction: %w", err)
+	}
+
+	products := make([]*entity.Product, 0, len(sorted))
+	for _, item := range sorted {
+		product, err := scanProduct(tx.QueryRowContext(ctx, query, item.Quantity, item.ProductID))
+		if err != nil {
+			tx.Rollback()
+			if err == sql.ErrNoRows {
+				return nil, r.stockFailure(ctx, item.ProductID, shortfall)
+			}
+			return nil, fmt.Errorf("failed to adjust stock: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit stock transaction: %w", err)
+	}
+
+	return products, nil
+}
+
+// stockFailure distinguishes a missing product from one without enough stock
+// or reservations after a conditional update matched no rows.
+func (r *productRepository) stockFailure(ctx context.Context, id uint, shortfall error) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to adjust stock: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %d", repository.ErrProductNotFound, id)
+	}
+	return fmt.Errorf("%w for product %d", shortfall, id)
+}
+
+func queryProducts(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*entity.Product, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var products []*entity.Product
 	for rows.Next() {
-		var product entity.Product
-		err := rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Description,
-			&product.Category,
-			&product.Price,
-			&product.Stock,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
+		product, err := scanProduct(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan product row: %w", err)
 		}
-		products = append(products, &product)
+		products = append(products, product)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error during product rows iteration: %w", err)
 	}
 
 	return products, nil
 }
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProduct(row rowScanner) (*entity.Product, error) {
+	var product entity.Product
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.Category,
+		&product.Price,
+		&product.Stock,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
@@ -0,0 +1,18 @@
+//go:build integration
+
+package postgres
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/database"
+	"github.com/rrxshxd/assignment1_advProg2/database/databasetest"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestProductRepository(t *testing.T) {
+	repositorytest.TestProductRepository(t, func(t *testing.T) repository.ProductRepository {
+		databasetest.Truncate(t, testDB, "products")
+		return NewProductRepository(database.NewDB(testDB, nil, nil))
+	})
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS products;
+
+CREATE TABLE products (
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category    TEXT NOT NULL DEFAULT '',
+    price       NUMERIC(12, 2) NOT NULL,
+    stock       INTEGER NOT NULL,
+    reserved    INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
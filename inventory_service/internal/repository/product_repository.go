@@ -1,11 +1,31 @@
 package repository
 
-import "github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+)
+
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrExcessRelease     = errors.New("release exceeds reserved stock")
+)
 
 type ProductRepository interface {
-	Create(product *entity.Product) error
-	FindByID(id uint) (*entity.Product, error)
-	Update(product *entity.Product) error
-	Delete(id uint) error
-	FindAll(page, limit int, filters map[string]interface{}) ([]*entity.Product, error)
+	Create(ctx context.Context, product *entity.Product) error
+	FindByID(ctx context.Context, id uint) (*entity.Product, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]*entity.Product, error)
+	Update(ctx context.Context, product *entity.Product) error
+	// Patch writes only the fields set in patch and returns the result, so
+	// it cannot undo a concurrent change to the fields it leaves alone.
+	Patch(ctx context.Context, id uint, patch entity.ProductPatch) (*entity.Product, error)
+	Delete(ctx context.Context, id uint) error
+	FindAll(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*entity.Product, error)
+	// ReserveStock moves the quantities from stock to the product's reserved
+	// count, failing with ErrInsufficientStock if any product has too little.
+	ReserveStock(ctx context.Context, items []entity.StockItem) ([]*entity.Product, error)
+	// ReleaseStock moves reserved quantities back to stock, failing with
+	// ErrExcessRelease if any product has fewer reserved.
+	ReleaseStock(ctx context.Context, items []entity.StockItem) ([]*entity.Product, error)
 }
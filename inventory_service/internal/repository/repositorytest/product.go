@@ -0,0 +1,457 @@
+// Package repositorytest holds contract tests that every repository
+// implementation must pass, whatever storage it uses.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"sync"
+	"testing"
+)
+
+// ProductFactory returns an empty repository. It is called once per subtest.
+type ProductFactory func(t *testing.T) repository.ProductRepository
+
+// TestProductRepository runs the ProductRepository contract against the
+// repositories returned by newRepo.
+func TestProductRepository(t *testing.T, newRepo ProductFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.ProductRepository)
+	}{
+		{"CreateAndFind", testProductCreateAndFind},
+		{"FindMissing", testProductFindMissing},
+		{"Update", testProductUpdate},
+		{"UpdateMissing", testProductUpdateMissing},
+		{"Patch", testProductPatch},
+		{"PatchMissing", testProductPatchMissing},
+		{"Delete", testProductDelete},
+		{"FindAllFilters", testProductFindAllFilters},
+		{"FindAllPagination", testProductFindAllPagination},
+		{"FindByIDs", testProductFindByIDs},
+		{"ReserveStock", testProductReserveStock},
+		{"ReserveStockAllOrNothing", testProductReserveStockAllOrNothing},
+		{"ReleaseStock", testProductReleaseStock},
+		{"ConcurrentCreate", testProductConcurrentCreate},
+		{"ConcurrentReserve", testProductConcurrentReserve},
+		{"ConcurrentPatchAndReserve", testProductConcurrentPatchAndReserve},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func createProduct(t *testing.T, repo repository.ProductRepository, product entity.Product) *entity.Product {
+	t.Helper()
+	if err := repo.Create(context.Background(), &product); err != nil {
+		t.Fatalf("Create(%q): %v", product.Name, err)
+	}
+	return &product
+}
+
+func testProductCreateAndFind(t *testing.T, repo repository.ProductRepository) {
+	ctx := context.Background()
+	first := createProduct(t, repo, entity.Product{Name: "Keyboard", Description: "Mechanical", Category: "peripherals", Price: 49.5, Stock: 7})
+	second := createProduct(t, repo, entity.Product{Name: "Mouse", Category: "peripherals", Price: 19, Stock: 3})
+
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("IDs = %d, %d, want increasing non-zero IDs", first.ID, second.ID)
+	}
+
+	got, err := repo.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Name != "Keyboard" || got.Description != "Mechanical" || got.Category != "peripherals" || got.Price != 49.5 || got.Stock != 7 {
+		t.Errorf("FindByID = %+v, want the created product", got)
+	}
+	if got.CreatedAt.IsZero() || got.UpdatedAt.IsZero() {
+		t.Errorf("timestamps not set: created %v, updated %v", got.CreatedAt, got.UpdatedAt)
+	}
+}
+
+func testProductFindMissing(t *testing.T, repo repository.ProductRepository) {
+	if _, err := repo.FindByID(context.Background(), 404); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("FindByID(404) error = %v, want ErrProductNotFound", err)
+	}
+}
+
+func testProductUpdate(t *testing.T, repo repository.ProductRepository) {
+	ctx := context.Background()
+	product := createProduct(t, repo, entity.Product{Name: "Monitor", Category: "displays", Price: 200, Stock: 2})
+	before, err := repo.FindByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+
+	update := *before
+	update.Name = "Monitor 27\""
+	update.Price = 180
+	update.Stock = 5
+	if err := repo.Update(ctx, &update); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if update.UpdatedAt.Before(before.UpdatedAt) {
+		t.Errorf("Update set UpdatedAt to %v, before the previous %v", update.UpdatedAt, before.UpdatedAt)
+	}
+
+	got, err := repo.FindByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Name != update.Name || got.Price != 180 || got.Stock != 5 || got.Category != "displays" {
+		t.Errorf("FindByID after Update = %+v", got)
+	}
+	if !got.CreatedAt.Equal(before.CreatedAt) {
+		t.Errorf("Update changed CreatedAt from %v to %v", before.CreatedAt, got.CreatedAt)
+	}
+}
+
+func testProductUpdateMissing(t *testing.T, repo repository.ProductRepository) {
+	err := repo.Update(context.Background(), &entity.Product{ID: 404, Name: "Ghost"})
+	if !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Update(404) error = %v, want ErrProductNotFound", err)
+	}
+}
+
+func testProductPatch(t *testing.T, repo repository.ProductRepository) {
+	ctx := context.Background()
+	product := createProduct(t, repo, entity.Product{Name: "Lamp", Description: "Brass", Category: "lighting", Price: 30, Stock: 7})
+	before, err := repo.FindByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+
+	price, description := 25.0, ""
+	patched, err := repo.Patch(ctx, product.ID, entity.ProductPatch{Price: &price, Description: &description})
+	if err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if patched.Name != "Lamp" || patched.Description != "" || patched.Category != "lighting" || patched.Price != 25 || patched.Stock != 7 {
+		t.Errorf("Patch = %+v, want only the price and description changed", patched)
+	}
+	if patched.UpdatedAt.Before(before.UpdatedAt) || !patched.CreatedAt.Equal(before.CreatedAt) {
+		t.Errorf("Patch timestamps = %v, %v, want the creation time kept and the update time advanced", patched.CreatedAt, patched.UpdatedAt)
+	}
+
+	got, err := repo.FindByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Price != 25 || got.Description != "" || got.Stock != 7 {
+		t.Errorf("FindByID after Patch = %+v", got)
+	}
+
+	stock := 0
+	if patched, err = repo.Patch(ctx, product.ID, entity.ProductPatch{Stock: &stock}); err != nil || patched.Stock != 0 || patched.Price != 25 {
+		t.Errorf("Patch(stock) = %+v, %v, want stock 0 and the price kept", patched, err)
+	}
+}
+
+func testProductPatchMissing(t *testing.T, repo repository.ProductRepository) {
+	name := "Ghost"
+	if _, err := repo.Patch(context.Background(), 404, entity.ProductPatch{Name: &name}); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Patch(404) error = %v, want ErrProductNotFound", err)
+	}
+}
+
+func testProductDelete(t *testing.T, repo repository.ProductRepository) {
+	ctx := context.Background()
+	product := createProduct(t, repo, entity.Product{Name: "Cable", Price: 5, Stock: 100})
+
+	if err := repo.Delete(ctx, product.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, product.ID); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("FindByID after Delete error = %v, want ErrProductNotFound", err)
+	}
+	if err := repo.Delete(ctx, product.ID); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("second Delete error = %v, want ErrProductNotFound", err)
+	}
+}
+
+func seedCatalog(t *testing.T, repo repository.ProductRepository) {
+	t.Helper()
+	createProduct(t, repo, entity.Product{Name: "Red Widget", Category: "widgets", Price: 5, Stock: 1})
+	createProduct(t, repo, entity.Product{Name: "Blue widget", Category: "widgets", Price: 15, Stock: 1})
+	createProduct(t, repo, entity.Product{Name: "Gadget", Category: "gadgets", Price: 25, Stock: 1})
+	createProduct(t, repo, entity.Product{Name: "WIDGET deluxe", Category: "gadgets", Price: 35, Stock: 1})
+	createProduct(t, repo, entity.Product{Name: "Sprocket", Category: "widgets", Price: 45, Stock: 1})
+}
+
+func productNames(products []*entity.Product) []string {
+	names := make([]string, len(products))
+	for i, product := range products {
+		names[i] = product.Name
+	}
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testProductFindAllFilters(t *testing.T, repo repository.ProductRepository) {
+	seedCatalog(t, repo)
+
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    []string
+	}{
+		{"none", map[string]interface{}{}, []string{"Red Widget", "Blue widget", "Gadget", "WIDGET deluxe", "Sprocket"}},
+		{"category", map[string]interface{}{"category": "gadgets"}, []string{"Gadget", "WIDGET deluxe"}},
+		{"price range", map[string]interface{}{"min_price": 15.0, "max_price": 35.0}, []string{"Blue widget", "Gadget", "WIDGET deluxe"}},
+		{"name is case-insensitive substring", map[string]interface{}{"name": "widget"}, []string{"Red Widget", "Blue widget", "WIDGET deluxe"}},
+		{"combined", map[string]interface{}{"name": "widget", "category": "widgets", "min_price": 10.0}, []string{"Blue widget"}},
+		{"no match", map[string]interface{}{"category": "none"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, err := repo.FindAll(context.Background(), 1, 10, tt.filters)
+			if err != nil {
+				t.Fatalf("FindAll: %v", err)
+			}
+			if got := productNames(products); !equalStrings(got, tt.want) {
+				t.Errorf("FindAll(%v) = %q, want %q", tt.filters, got, tt.want)
+			}
+		})
+	}
+}
+
+func testProductFindAllPagination(t *testing.T, repo repository.ProductRepository) {
+	seedCatalog(t, repo)
+
+	tests := []struct {
+		page, limit int
+		want        []string
+	}{
+		{1, 2, []string{"Red Widget", "Blue widget"}},
+		{2, 2, []string{"Gadget", "WIDGET deluxe"}},
+		{3, 2, []string{"Sprocket"}},
+		{4, 2, nil},
+		{0, 0, []string{"Red Widget", "Blue widget", "Gadget", "WIDGET deluxe", "Sprocket"}},
+	}
+	for _, tt := range tests {
+		products, err := repo.FindAll(context.Background(), tt.page, tt.limit, map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("FindAll(page %d, limit %d): %v", tt.page, tt.limit, err)
+		}
+		if got := productNames(products); !equalStrings(got, tt.want) {
+			t.Errorf("FindAll(page %d, limit %d) = %q, want %q", tt.page, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func testProductFindByIDs(t *testing.T, repo repository.ProductRepository) {
+	a := createProduct(t, repo, entity.Product{Name: "A", Price: 1, Stock: 1})
+	createProduct(t, repo, entity.Product{Name: "B", Price: 1, Stock: 1})
+	c := createProduct(t, repo, entity.Product{Name: "C", Price: 1, Stock: 1})
+
+	products, err := repo.FindByIDs(context.Background(), []uint{c.ID, 404, a.ID})
+	if err != nil {
+		t.Fatalf("FindByIDs: %v", err)
+	}
+	if got := productNames(products); !equalStrings(got, []string{"A", "C"}) {
+		t.Errorf("FindByIDs = %q, want [A C] in ID order without the missing ID", got)
+	}
+}
+
+func stockOf(t *testing.T, repo repository.ProductRepository, id uint) int {
+	t.Helper()
+	product, err := repo.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("FindByID(%d): %v", id, err)
+	}
+	return product.Stock
+}
+
+func testProductReserveStock(t *testing.T, repo repository.ProductRepository) {
+	ctx := context.Background()
+	a := createProduct(t, repo, entity.Product{Name: "A", Price: 1, Stock: 5})
+	b := createProduct(t, repo, entity.Product{Name: "B", Price: 1, Stock: 2})
+
+	products, err := repo.ReserveStock(ctx, []entity.StockItem{{ProductID: b.ID, Quantity: 2}, {ProductID: a.ID, Quantity: 3}})
+	if err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	if len(products) != 2 || products[0].ID != a.ID || products[0].Stock != 2 || products[1].ID != b.ID || products[1].Stock != 0 {
+		t.Errorf("ReserveStock returned %+v, want A with 2 and B with 0 in ID order", products)
+	}
+	if got := stockOf(t, repo, a.ID); got != 2 {
+		t.Errorf("stock of A = %d, want 2", got)
+	}
+
+	if _, err := repo.ReserveStock(ctx, []entity.StockItem{{ProductID: b.ID, Quantity: 1}}); !errors.Is(err, repository.ErrInsufficientStock) {
+		t.Errorf("ReserveStock beyond stock error = %v, want ErrInsufficientStock", err)
+	}
+	if _, err := repo.ReserveStock(ctx, []entity.StockItem{{ProductID: 404, Quantity: 1}}); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("ReserveStock of missing product error = %v, want ErrProductNotFound", err)
+	}
+}
+
+func testProductReserveStockAllOrNothing(t *testing.T, repo repository.ProductRepository) {
+	a := createProduct(t, repo, entity.Product{Name: "A", Price: 1, Stock: 5})
+	b := createProduct(t, repo, entity.Product{Name: "B", Price: 1, Stock: 1})
+
+	_, err := repo.ReserveStock(context.Background(), []entity.StockItem{{ProductID: a.ID, Quantity: 3}, {ProductID: b.ID, Quantity: 2}})
+	if !errors.Is(err, repository.ErrInsufficientStock) {
+		t.Fatalf("ReserveStock error = %v, want ErrInsufficientStock", err)
+	}
+	if got := stockOf(t, repo, a.ID); got != 5 {
+		t.Errorf("stock of A after failed reservation = %d, want 5", got)
+	}
+}
+
+func testProductReleaseStock(t *testing.T, repo repository.ProductRepository) {
+	ctx := context.Background()
+	a := createProduct(t, repo, entity.Product{Name: "A", Price: 1, Stock: 5})
+	b := createProduct(t, repo, entity.Product{Name: "B", Price: 1, Stock: 5})
+	if _, err := repo.ReserveStock(ctx, []entity.StockItem{{ProductID: a.ID, Quantity: 3}, {ProductID: b.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+
+	products, err := repo.ReleaseStock(ctx, []entity.StockItem{{ProductID: a.ID, Quantity: 2}})
+	if err != nil {
+		t.Fatalf("ReleaseStock: %v", err)
+	}
+	if len(products) != 1 || products[0].Stock != 4 {
+		t.Errorf("ReleaseStock returned %+v, want stock 4", products)
+	}
+
+	// A has 1 left reserved, so the whole release fails and B keeps its
+	// reservation.
+	_, err = repo.ReleaseStock(ctx, []entity.StockItem{{ProductID: a.ID, Quantity: 2}, {ProductID: b.ID, Quantity: 1}})
+	if !errors.Is(err, repository.ErrExcessRelease) {
+		t.Errorf("ReleaseStock beyond the reservation error = %v, want ErrExcessRelease", err)
+	}
+	if got := stockOf(t, repo, a.ID); got != 4 {
+		t.Errorf("stock of A after failed release = %d, want 4", got)
+	}
+	if got := stockOf(t, repo, b.ID); got != 4 {
+		t.Errorf("stock of B after failed release = %d, want 4", got)
+	}
+
+	if _, err := repo.ReleaseStock(ctx, []entity.StockItem{{ProductID: a.ID, Quantity: 1}, {ProductID: b.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("ReleaseStock of the remaining reservations: %v", err)
+	}
+	if got := stockOf(t, repo, a.ID); got != 5 {
+		t.Errorf("stock of A after releasing everything = %d, want 5", got)
+	}
+	if _, err := repo.ReleaseStock(ctx, []entity.StockItem{{ProductID: 404, Quantity: 1}}); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("ReleaseStock of missing product error = %v, want ErrProductNotFound", err)
+	}
+}
+
+func testProductConcurrentCreate(t *testing.T, repo repository.ProductRepository) {
+	const n = 20
+	ids := make(chan uint, n)
+	errs := make(chan error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			product := entity.Product{Name: "Concurrent", Price: 1, Stock: 1}
+			if err := repo.Create(context.Background(), &product); err != nil {
+				errs <- err
+				return
+			}
+			ids <- product.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Create: %v", err)
+	}
+	seen := make(map[uint]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("ID %d handed out twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func testProductConcurrentReserve(t *testing.T, repo repository.ProductRepository) {
+	const stock, buyers = 10, 25
+	product := createProduct(t, repo, entity.Product{Name: "Limited", Price: 1, Stock: stock})
+
+	var (
+		wg              sync.WaitGroup
+		mu              sync.Mutex
+		reserved, short int
+	)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.ReserveStock(context.Background(), []entity.StockItem{{ProductID: product.ID, Quantity: 1}})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				reserved++
+			case errors.Is(err, repository.ErrInsufficientStock):
+				short++
+			default:
+				t.Errorf("ReserveStock: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != stock || short != buyers-stock {
+		t.Errorf("%d reservations succeeded and %d ran short, want %d and %d", reserved, short, stock, buyers-stock)
+	}
+	if got := stockOf(t, repo, product.ID); got != 0 {
+		t.Errorf("stock after concurrent reservations = %d, want 0", got)
+	}
+}
+
+// testProductConcurrentPatchAndReserve checks that patches which leave stock
+// alone do not write back a stock level read before a concurrent reservation.
+func testProductConcurrentPatchAndReserve(t *testing.T, repo repository.ProductRepository) {
+	const stock = 20
+	product := createProduct(t, repo, entity.Product{Name: "Busy", Price: 1, Stock: stock})
+
+	var wg sync.WaitGroup
+	for i := 0; i < stock; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.ReserveStock(context.Background(), []entity.StockItem{{ProductID: product.ID, Quantity: 1}}); err != nil {
+				t.Errorf("ReserveStock: %v", err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			price := float64(i + 1)
+			if _, err := repo.Patch(context.Background(), product.ID, entity.ProductPatch{Price: &price}); err != nil {
+				t.Errorf("Patch: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := stockOf(t, repo, product.ID); got != 0 {
+		t.Errorf("stock after concurrent reservations and patches = %d, want 0", got)
+	}
+}
@@ -1,13 +1,21 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/metrics"
 	"github.com/rrxshxd/assignment1_advProg2/inventory_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
 	"time"
 )
 
+var (
+	ErrInvalidStockRequest = errors.New("invalid stock request")
+	ErrInvalidProduct      = errors.New("invalid product")
+)
+
 type ProductUseCase struct {
 	productRepo repository.ProductRepository
 }
@@ -16,84 +24,112 @@ func NewProductUseCase(productRepo repository.ProductRepository) *ProductUseCase
 	return &ProductUseCase{productRepo: productRepo}
 }
 
-func (uc *ProductUseCase) CreateProduct(product *entity.Product) error {
-	if product.Name == "" || product.Category == "" || product.Category == "" {
-		return errors.New("Invalid product data")
+func (uc *ProductUseCase) CreateProduct(ctx context.Context, product *entity.Product) error {
+	if err := validateProduct(product); err != nil {
+		return err
 	}
 
 	now := time.Now()
 	product.CreatedAt = now
 	product.UpdatedAt = now
-	err := uc.productRepo.Create(product)
+	err := uc.productRepo.Create(ctx, product)
 	if err != nil {
 		return err
 	}
 
+	logging.FromContext(ctx).InfoContext(ctx, "product created", "product_id", product.ID)
 	return nil
 }
 
-func (uc *ProductUseCase) GetProduct(id uint) (*entity.Product, error) {
-	product, err := uc.productRepo.FindByID(id)
+func (uc *ProductUseCase) GetProduct(ctx context.Context, id uint) (*entity.Product, error) {
+	product, err := uc.productRepo.FindByID(ctx, id)
 	if err != nil {
-		if err == fmt.Errorf("Product with such id can't be found: %d", id) {
-			return nil, fmt.Errorf("Product with such id can't be found: %d", id)
-		}
 		return nil, err
 	}
 
 	return product, nil
 }
 
-func (uc *ProductUseCase) UpdateProduct(product *entity.Product) error {
-	existingProduct, err := uc.productRepo.FindByID(product.ID)
-	if err != nil {
-		if err == fmt.Errorf("Product with such id can't be found: %d", product.ID) {
-			return fmt.Errorf("Product with such id can't be found: %d", product.ID)
-		}
-		return err
+// UpdateProduct applies patch to a product and returns the result. Only the
+// fields set in patch are written, so stock reserved or released meanwhile is
+// kept unless the patch sets stock itself.
+func (uc *ProductUseCase) UpdateProduct(ctx context.Context, id uint, patch entity.ProductPatch) (*entity.Product, error) {
+	if err := validatePatch(patch); err != nil {
+		return nil, err
 	}
 
-	if product.Name != "" {
-		existingProduct.Name = product.Name
-	}
-	if product.Description != "" {
-		existingProduct.Description = product.Description
-	}
-	if product.Category != "" {
-		existingProduct.Category = product.Category
-	}
-	if product.Price > 0 {
-		existingProduct.Price = product.Price
-	}
-	if product.Stock >= 0 { // 0 is valid for stock
-		existingProduct.Stock = product.Stock
+	product, err := uc.productRepo.Patch(ctx, id, patch)
+	if err != nil {
+		return nil, err
 	}
 
-	existingProduct.UpdatedAt = time.Now()
+	logging.FromContext(ctx).InfoContext(ctx, "product updated", "product_id", product.ID)
+	return product, nil
+}
 
-	err = uc.productRepo.Update(existingProduct)
+// ReplaceProduct overwrites every field of an existing product but its ID and
+// creation time.
+func (uc *ProductUseCase) ReplaceProduct(ctx context.Context, product *entity.Product) error {
+	existingProduct, err := uc.productRepo.FindByID(ctx, product.ID)
 	if err != nil {
 		return err
 	}
 
-	*product = *existingProduct
+	product.CreatedAt = existingProduct.CreatedAt
+	return uc.save(ctx, product)
+}
+
+func (uc *ProductUseCase) save(ctx context.Context, product *entity.Product) error {
+	if err := validateProduct(product); err != nil {
+		return err
+	}
+
+	product.UpdatedAt = time.Now()
+	if err := uc.productRepo.Update(ctx, product); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "product updated", "product_id", product.ID)
+	return nil
+}
+
+func validateProduct(product *entity.Product) error {
+	switch {
+	case product.Name == "" || product.Category == "":
+		return fmt.Errorf("%w: name and category are required", ErrInvalidProduct)
+	case product.Price <= 0:
+		return fmt.Errorf("%w: price must be positive, got %v", ErrInvalidProduct, product.Price)
+	case product.Stock < 0:
+		return fmt.Errorf("%w: stock cannot be negative, got %d", ErrInvalidProduct, product.Stock)
+	}
+	return nil
+}
 
+// validatePatch applies the rules of validateProduct to the fields set in
+// patch. The stored product already satisfies them, so the patched one will.
+func validatePatch(patch entity.ProductPatch) error {
+	switch {
+	case patch.Name != nil && *patch.Name == "", patch.Category != nil && *patch.Category == "":
+		return fmt.Errorf("%w: name and category are required", ErrInvalidProduct)
+	case patch.Price != nil && *patch.Price <= 0:
+		return fmt.Errorf("%w: price must be positive, got %v", ErrInvalidProduct, *patch.Price)
+	case patch.Stock != nil && *patch.Stock < 0:
+		return fmt.Errorf("%w: stock cannot be negative, got %d", ErrInvalidProduct, *patch.Stock)
+	}
 	return nil
 }
 
-func (uc *ProductUseCase) DeleteProduct(id uint) error {
-	err := uc.productRepo.Delete(id)
+func (uc *ProductUseCase) DeleteProduct(ctx context.Context, id uint) error {
+	err := uc.productRepo.Delete(ctx, id)
 	if err != nil {
-		if err == fmt.Errorf("Product with such id can't be found: %d", id) {
-			return fmt.Errorf("Product with such id can't be found: %d", id)
-		}
 		return err
 	}
 
+	logging.FromContext(ctx).InfoContext(ctx, "product deleted", "product_id", id)
 	return nil
 }
 
-func (uc *ProductUseCase) GetAll(page, limit int, filters map[string]interface{}) ([]*entity.Product, error) {
+func (uc *ProductUseCase) GetAll(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*entity.Product, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -101,10 +137,84 @@ func (uc *ProductUseCase) GetAll(page, limit int, filters map[string]interface{}
 		limit = 10
 	}
 
-	products, err := uc.productRepo.FindAll(page, limit, filters)
+	products, err := uc.productRepo.FindAll(ctx, page, limit, filters)
 	if err != nil {
 		return nil, err
 	}
 
 	return products, nil
 }
+
+// GetProducts returns the products found for ids together with the IDs that
+// do not exist.
+func (uc *ProductUseCase) GetProducts(ctx context.Context, ids []uint) ([]*entity.Product, []uint, error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	products, err := uc.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[uint]bool, len(products))
+	for _, product := range products {
+		found[product.ID] = true
+	}
+
+	var missing []uint
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+			found[id] = true
+		}
+	}
+
+	return products, missing, nil
+}
+
+func (uc *ProductUseCase) ReserveStock(ctx context.Context, items []entity.StockItem) ([]*entity.Product, error) {
+	merged, err := mergeStockItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := uc.productRepo.ReserveStock(ctx, merged)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		metrics.StockOuts.Inc()
+		logging.FromContext(ctx).WarnContext(ctx, "stock reservation rejected", "error", err)
+	}
+	return products, err
+}
+
+func (uc *ProductUseCase) ReleaseStock(ctx context.Context, items []entity.StockItem) ([]*entity.Product, error) {
+	merged, err := mergeStockItems(items)
+	if err != nil {
+		return nil, err
+	}
+	return uc.productRepo.ReleaseStock(ctx, merged)
+}
+
+// mergeStockItems validates quantities and folds repeated products into one
+// item so each row is only touched once per transaction.
+func mergeStockItems(items []entity.StockItem) ([]entity.StockItem, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: at least one item is required", ErrInvalidStockRequest)
+	}
+
+	index := make(map[uint]int, len(items))
+	merged := make([]entity.StockItem, 0, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity for product %d must be positive", ErrInvalidStockRequest, item.ProductID)
+		}
+		if i, ok := index[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+
+	return merged, nil
+}
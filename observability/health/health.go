@@ -0,0 +1,76 @@
+// Package health serves the liveness and readiness endpoints of every HTTP
+// service.
+package health
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// Check reports whether a dependency the service relies on is usable.
+type Check func(ctx context.Context) error
+
+type Controller struct {
+	checks map[string]Check
+}
+
+func NewController(checks map[string]Check) *Controller {
+	return &Controller{checks: checks}
+}
+
+// Liveness only reports that the process is able to serve requests.
+func (c *Controller) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": statusOK})
+}
+
+// Readiness runs every dependency check concurrently and reports 503 if any
+// of them fails or does not answer within readinessTimeout. The response
+// only names each check's status; why a check failed is logged, since the
+// error can reveal addresses and internals of the dependency.
+func (c *Controller) Readiness(ctx *gin.Context) {
+	reqCtx := ctx.Request.Context()
+	checkCtx, cancel := context.WithTimeout(reqCtx, readinessTimeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		ready   = true
+		results = make(map[string]string, len(c.checks))
+	)
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			err := check(checkCtx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logging.FromContext(reqCtx).WarnContext(reqCtx, "readiness check failed", "check", name, "error", err)
+				ready = false
+				results[name] = statusUnavailable
+				return
+			}
+			results[name] = statusOK
+		}(name, check)
+	}
+	wg.Wait()
+
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": statusUnavailable, "checks": results})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "ready", "checks": results})
+}
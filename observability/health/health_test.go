@@ -0,0 +1,128 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func serve(t *testing.T, ctx context.Context, controller *Controller, path string) (int, response, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", controller.Liveness)
+	router.GET("/readyz", controller.Readiness)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx))
+
+	var body response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return rec.Code, body, rec.Body.String()
+}
+
+func TestLiveness(t *testing.T) {
+	failing := NewController(map[string]Check{
+		"database": func(context.Context) error { return errors.New("down") },
+	})
+
+	code, body, _ := serve(t, context.Background(), failing, "/healthz")
+	if code != http.StatusOK || body.Status != "ok" {
+		t.Errorf("liveness = %d %+v, want 200 ok even with failing dependencies", code, body)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error {
+		return errors.New("dial tcp 10.0.3.7:5432: connection refused")
+	}
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+		wantLogged []string
+		hidden     string
+	}{
+		{
+			name:       "no checks",
+			checks:     map[string]Check{},
+			wantCode:   http.StatusOK,
+			wantStatus: "ready",
+			wantChecks: map[string]string{},
+		},
+		{
+			name:       "all ready",
+			checks:     map[string]Check{"database": ok, "inventory_service": ok},
+			wantCode:   http.StatusOK,
+			wantStatus: "ready",
+			wantChecks: map[string]string{"database": "ok", "inventory_service": "ok"},
+		},
+		{
+			name:       "one failing",
+			checks:     map[string]Check{"database": failing, "inventory_service": ok},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "unavailable", "inventory_service": "ok"},
+			wantLogged: []string{"check=database", "10.0.3.7:5432"},
+			hidden:     "10.0.3.7:5432",
+		},
+		{
+			name:       "one not answering",
+			checks:     map[string]Check{"database": ok, "user_service": hanging},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "ok", "user_service": "unavailable"},
+			wantLogged: []string{"check=user_service", "deadline exceeded"},
+			hidden:     "deadline exceeded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			ctx := logging.NewContext(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+			ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer cancel()
+
+			code, body, raw := serve(t, ctx, NewController(tt.checks), "/readyz")
+			if code != tt.wantCode || body.Status != tt.wantStatus {
+				t.Errorf("readiness = %d %q, want %d %q", code, body.Status, tt.wantCode, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(body.Checks, tt.wantChecks) {
+				t.Errorf("checks = %v, want %v", body.Checks, tt.wantChecks)
+			}
+			for _, want := range tt.wantLogged {
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("log %q does not mention %q", logs.String(), want)
+				}
+			}
+			if tt.hidden != "" && strings.Contains(raw, tt.hidden) {
+				t.Errorf("response %s reveals %q", raw, tt.hidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// UnaryServerInterceptor is the gRPC counterpart of HTTPMiddleware, reading
+// the request ID from x-request-id metadata and logging the caller's address.
+func UnaryServerInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, reqLogger := withRequestLogger(ctx, logger)
+		resp, err := handler(ctx, req)
+		logCall(ctx, reqLogger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func StreamServerInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, reqLogger := withRequestLogger(ss.Context(), logger)
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, reqLogger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func withRequestLogger(ctx context.Context, logger *slog.Logger) (context.Context, *slog.Logger) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestid.MetadataKey); len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+	id = requestid.Resolve(id)
+
+	reqLogger := logger.With("request_id", id)
+	return NewContext(requestid.NewContext(ctx, id), reqLogger), reqLogger
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, method string, start time.Time, err error) {
+	attrs := []any{
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+	if err != nil {
+		logger.WarnContext(ctx, "grpc call failed", append(attrs, "error", status.Convert(err).Message())...)
+		return
+	}
+	logger.InfoContext(ctx, "grpc call", attrs...)
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
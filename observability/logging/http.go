@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// HTTPMiddleware attaches a request-scoped logger carrying the request ID to
+// the request context and writes one access log per request. The ID is taken
+// from the context when earlier middleware already chose one, and otherwise
+// from the caller's X-Request-ID header.
+func HTTPMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		id := requestid.FromContext(ctx.Request.Context())
+		if id == "" {
+			id = requestid.Resolve(ctx.GetHeader(requestid.Header))
+			ctx.Header(requestid.Header, id)
+			ctx.Request = ctx.Request.WithContext(requestid.NewContext(ctx.Request.Context(), id))
+		}
+
+		reqLogger := logger.With("request_id", id)
+		ctx.Request = ctx.Request.WithContext(NewContext(ctx.Request.Context(), reqLogger))
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []any{
+			"method", ctx.Request.Method,
+			"route", ctx.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", ctx.ClientIP(),
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, "error", ctx.Errors.String())
+		}
+		reqLogger.Log(ctx.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// Recovery replaces gin's default recovery so panics are logged as JSON with
+// the request's logger.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, recovered any) {
+		FromContext(ctx.Request.Context()).ErrorContext(ctx.Request.Context(), "panic in http handler",
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,34 @@
+// Package metrics provides the Prometheus collectors every service exports:
+// HTTP and gRPC server metrics and database pool statistics. Each service
+// keeps its own registry, so several services can share a process without
+// their metric names clashing.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// NewRegistry returns a registry that already exports the Go runtime and
+// process metrics.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return registry
+}
+
+// RegisterDB exports the connection pool statistics of db. A pool registered
+// under the same name before, by an earlier instance of the service in this
+// process, is replaced.
+func RegisterDB(registry prometheus.Registerer, db *sql.DB, name string) {
+	collector := collectors.NewDBStatsCollector(db, name)
+	var registered prometheus.AlreadyRegisteredError
+	if err := registry.Register(collector); errors.As(err, &registered) {
+		registry.Unregister(registered.ExistingCollector)
+		registry.MustRegister(collector)
+	} else if err != nil {
+		panic(err)
+	}
+}
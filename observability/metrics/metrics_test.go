@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"database/sql"
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestRegisterDBReplacesEarlierPool(t *testing.T) {
+	first, err := sql.Open("postgres", "postgres://localhost/first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := sql.Open("postgres", "postgres://localhost/second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.SetMaxOpenConns(1)
+	second.SetMaxOpenConns(7)
+
+	registry := prometheus.NewRegistry()
+	RegisterDB(registry, first, "test")
+	RegisterDB(registry, second, "test")
+
+	expected := `
+# HELP go_sql_max_open_connections Maximum number of open connections to the database.
+# TYPE go_sql_max_open_connections gauge
+go_sql_max_open_connections{db_name="test"} 7
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "go_sql_max_open_connections"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	tests := []struct {
+		fullMethod    string
+		service, name string
+	}{
+		{"/inventory.InventoryService/GetProduct", "inventory.InventoryService", "GetProduct"},
+		{"GetProduct", "unknown", "GetProduct"},
+	}
+	for _, tt := range tests {
+		service, name := splitMethod(tt.fullMethod)
+		if service != tt.service || name != tt.name {
+			t.Errorf("splitMethod(%q) = %q, %q, want %q, %q", tt.fullMethod, service, name, tt.service, tt.name)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package requestid
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor forwards the request ID in the context to the
+// called service as x-request-id metadata.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := FromContext(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
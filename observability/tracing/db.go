@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DBTracer traces the calls of a Postgres repository package.
+type DBTracer struct {
+	tracer trace.Tracer
+}
+
+// NewDBTracer names the tracer after the instrumented package, e.g.
+// "github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository/postgres".
+func NewDBTracer(name string) *DBTracer {
+	return &DBTracer{tracer: otel.Tracer(name)}
+}
+
+// Start opens a client span around one repository call and scopes the
+// context logger to it. Callers pass the call's final error to End, usually
+// from a deferred closure.
+func (t *DBTracer) Start(ctx context.Context, name, operation, table string) (context.Context, trace.Span) {
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).With("db_call", name))
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBSQLTable(table),
+		),
+	)
+}
+
+// End only logs at debug level; callers decide whether an error is worth
+// reporting.
+func (t *DBTracer) End(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logging.FromContext(ctx).DebugContext(ctx, "database call failed", "error", err)
+	} else {
+		logging.FromContext(ctx).DebugContext(ctx, "database call")
+	}
+	span.End()
+}
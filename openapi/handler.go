@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"net/http"
+)
+
+const swaggerUIVersion = "5.17.14"
+
+// Handler serves doc as JSON.
+func Handler(doc *openapi3.T) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, doc)
+	}
+}
+
+var uiPage = template.Must(template.New("ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js"></script>
+<script>{{.Script}}</script>
+</body>
+</html>
+`))
+
+// UI serves a Swagger UI page for the document at specURL. The UI's assets
+// come from unpkg, so the page sets its own Content-Security-Policy, pinning
+// the inline script by hash.
+func UI(title, specURL string) gin.HandlerFunc {
+	config, _ := json.Marshal(map[string]string{"url": specURL, "dom_id": "#swagger-ui"})
+	script := template.JS(fmt.Sprintf("window.ui = SwaggerUIBundle(%s);", config))
+	hash := sha256.Sum256([]byte(script))
+	policy := "default-src 'none'; script-src https://unpkg.com 'sha256-" + base64.StdEncoding.EncodeToString(hash[:]) + "'; " +
+		"style-src https://unpkg.com; img-src 'self' data: https://unpkg.com; connect-src 'self'; frame-ancestors 'none'"
+	data := map[string]any{"Title": title, "Version": swaggerUIVersion, "Script": script}
+
+	return func(ctx *gin.Context) {
+		ctx.Header("Content-Security-Policy", policy)
+		ctx.Header("Content-Type", "text/html; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		uiPage.Execute(ctx.Writer, data)
+	}
+}
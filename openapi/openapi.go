@@ -0,0 +1,171 @@
+// Package openapi holds what the services need to publish their HTTP APIs as
+// OpenAPI 3 documents: schemas generated from the dto types, helpers for
+// building operations, a JSON and Swagger UI handler, and a request validator.
+// Each service writes its own document with these.
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const schemaPrefix = "#/components/schemas/"
+
+// Resolve links the references in doc to their component schemas, which the
+// validator needs, and checks the result is a valid document.
+func Resolve(ctx context.Context, doc *openapi3.T) error {
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		return err
+	}
+	return doc.Validate(ctx)
+}
+
+// GenerateSchemas builds a component schema for each value. Validation rules
+// come from the binding tags the handlers already enforce.
+func GenerateSchemas(values map[string]any) (openapi3.Schemas, error) {
+	schemas := openapi3.Schemas{}
+	for name, value := range values {
+		ref, err := openapi3gen.NewSchemaRefForValue(value, schemas, openapi3gen.SchemaCustomizer(bindingRules))
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		schemas[name] = ref
+	}
+	return schemas, nil
+}
+
+// QueryParameters describes the fields of a struct with form tags as query
+// parameters.
+func QueryParameters(value any) (openapi3.Parameters, error) {
+	t := reflect.TypeOf(value)
+	var parameters openapi3.Parameters
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("form")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema, err := openapi3gen.NewSchemaRefForValue(reflect.Zero(field.Type).Interface(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("query parameter %s: %w", name, err)
+		}
+		if err := bindingRules(name, field.Type, field.Tag, schema.Value); err != nil {
+			return nil, err
+		}
+		parameters = append(parameters, &openapi3.ParameterRef{Value: openapi3.NewQueryParameter(name).WithSchema(schema.Value)})
+	}
+	return parameters, nil
+}
+
+// bindingRules translates the validator rules in binding tags that have a
+// JSON Schema equivalent.
+func bindingRules(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	// Pointer fields in the dto types mark values that may be absent, not
+	// ones that may be null.
+	schema.Nullable = false
+
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if jsonName != "" && jsonName != "-" && hasRule(field.Tag.Get("binding"), "required") {
+				schema.Required = append(schema.Required, jsonName)
+			}
+		}
+	}
+
+	for _, rule := range strings.Split(tag.Get("binding"), ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			if t.Kind() == reflect.String {
+				schema.MinLength = 1
+			}
+		case "gt", "gte", "lt", "lte":
+			limit, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("field %s: invalid %s rule: %w", name, key, err)
+			}
+			if key == "gt" || key == "gte" {
+				schema.Min = &limit
+				schema.ExclusiveMin = key == "gt"
+			} else {
+				schema.Max = &limit
+				schema.ExclusiveMax = key == "lt"
+			}
+		case "min", "max":
+			limit, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("field %s: invalid %s rule: %w", name, key, err)
+			}
+			switch {
+			case t.Kind() == reflect.Slice && key == "min":
+				schema.MinItems = limit
+			case t.Kind() == reflect.Slice:
+				schema.MaxItems = &limit
+			case t.Kind() == reflect.String && key == "min":
+				schema.MinLength = limit
+			case t.Kind() == reflect.String:
+				schema.MaxLength = &limit
+			}
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				schema.Enum = append(schema.Enum, option)
+			}
+			if hasRule(tag.Get("binding"), "omitempty") && t.Kind() == reflect.String {
+				schema.Enum = append(schema.Enum, "")
+			}
+		}
+	}
+	return nil
+}
+
+func hasRule(binding, rule string) bool {
+	for _, candidate := range strings.Split(binding, ",") {
+		if candidate == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaRef refers to the component schema called name.
+func SchemaRef(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef(schemaPrefix+name, nil)
+}
+
+// JSONBody is a required JSON request body holding the named schema.
+func JSONBody(schema string) *openapi3.RequestBodyRef {
+	return &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(SchemaRef(schema))}
+}
+
+// JSONResponse is a JSON response holding the named schema.
+func JSONResponse(description, schema string) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(SchemaRef(schema))}
+}
+
+// EmptyResponse is a response without a body.
+func EmptyResponse(description string) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription(description)}
+}
+
+// IDParameter is the positive integer {id} path parameter.
+func IDParameter(description string) *openapi3.ParameterRef {
+	schema := openapi3.NewIntegerSchema().WithMin(1)
+	return &openapi3.ParameterRef{Value: openapi3.NewPathParameter("id").WithDescription(description).WithSchema(schema)}
+}
+
+// Responses maps status codes to their responses.
+func Responses(statuses map[int]*openapi3.ResponseRef) *openapi3.Responses {
+	responses := openapi3.NewResponsesWithCapacity(len(statuses))
+	for status, response := range statuses {
+		responses.Set(strconv.Itoa(status), response)
+	}
+	return responses
+}
@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// Validator rejects requests whose parameters or body do not match their
+// operation in doc, with the {"error": "..."} body every service uses.
+// Requests doc does not describe pass through untouched.
+func Validator(doc *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	options := &openapi3filter.Options{
+		SkipSettingDefaults: true,
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(ctx *gin.Context) {
+		route, pathParams, err := router.FindRoute(ctx.Request)
+		if err != nil {
+			ctx.Next()
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(ctx.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    ctx.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error": fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit),
+				})
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": validationMessage(err)})
+			return
+		}
+
+		ctx.Next()
+	}, nil
+}
+
+// validationMessage condenses a validation error to one line naming the
+// offending parameter or body field; the library's own messages embed the
+// whole schema.
+func validationMessage(err error) string {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return err.Error()
+	}
+
+	subject := "request body"
+	if requestErr.Parameter != nil {
+		subject = fmt.Sprintf("%s parameter %q", requestErr.Parameter.In, requestErr.Parameter.Name)
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			subject += " field " + strings.Join(pointer, ".")
+		}
+		return subject + ": " + schemaErr.Reason
+	}
+	if requestErr.Err != nil {
+		return subject + ": " + requestErr.Err.Error()
+	}
+	return subject + ": " + requestErr.Reason
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/dto"
+	"net/http"
+)
+
+func (c *Client) CreateOrder(ctx context.Context, request dto.CreateOrderRequest) (*dto.Order, error) {
+	var created dto.Order
+	if err := c.do(ctx, http.MethodPost, "/orders", request, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) GetOrder(ctx context.Context, id uint) (*dto.Order, error) {
+	var order dto.Order
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/orders/%d", id), nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (c *Client) UpdateOrderStatus(ctx context.Context, id uint, status dto.OrderStatus) error {
+	request := dto.UpdateOrderStatusRequest{Status: status}
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("/orders/%d", id), request, nil)
+}
+
+func (c *Client) CancelOrder(ctx context.Context, id uint) error {
+	return c.UpdateOrderStatus(ctx, id, dto.StatusCancelled)
+}
+
+// UserOrders returns every order of a user, newest first.
+func (c *Client) UserOrders(ctx context.Context, userID uint) ([]dto.Order, error) {
+	var list dto.OrderList
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/orders?user_id=%d", userID), nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
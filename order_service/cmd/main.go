@@ -1,35 +1,109 @@
 package main
 
 import (
-	"database/sql"
-	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"github.com/rrxshxd/assignment1_advProg2/observability/tracing"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/app"
 	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/config"
-	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/controller"
-	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository/postgres"
-	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/usecase"
-	"log"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
+	if err != nil {
+		slog.Error("Invalid log level", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "order_service", tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+		StdoutFile:   cfg.TracingFile,
+	})
+	if err != nil {
+		fatal(logger, "Failed to set up tracing", err)
+	}
+
+	application, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		fatal(logger, "Failed to start order service", err)
+	}
+	defer application.Close()
 
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "Failed to listen", err)
 	}
-	defer db.Close()
 
-	orderRepo := postgres.NewOrderRepository(db)
-	orderUseCase := usecase.NewOrderUseCase(orderRepo)
-	orderController := controller.NewOrderController(orderUseCase)
+	go func() {
+		logger.Info("Order gRPC server is running", "port", cfg.GRPCPort)
+		if err := application.GRPC.Serve(lis); err != nil {
+			fatal(logger, "Failed to serve gRPC", err)
+		}
+	}()
+
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: application.HTTP}
+	go func() {
+		logger.Info("Order HTTP server is running", "port", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal(logger, "Failed to serve HTTP", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("Shutting down, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout.String())
 
-	router := gin.Default()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
 
-	router.POST("/orders", orderController.CreateOrder)
-	router.GET("/orders/:id", orderController.GetOrder)
-	router.PATCH("/orders/:id", orderController.UpdateOrderStatus)
-	router.GET("/orders", orderController.GetUserOrders)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown", "error", err)
+	}
+	gracefulStop(shutdownCtx, application.GRPC)
 
-	router.Run(":" + cfg.Port)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Tracing shutdown", "error", err)
+	}
+}
+
+// fatal logs err and exits. Deferred calls do not run.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// gracefulStop drains in-flight RPCs, forcing the server closed if ctx
+// expires first. WatchOrder streams only end when their order does, so they
+// are usually what hits the deadline.
+func gracefulStop(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+	}
 }
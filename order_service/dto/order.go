@@ -0,0 +1,66 @@
+// Package dto defines the JSON bodies of the order service's HTTP API. The
+// handlers and the client package share these types so the two cannot drift
+// apart.
+package dto
+
+import "time"
+
+// The gateway names the authenticated caller in these headers. The order
+// service trusts them, so it must only be reachable through the gateway.
+const (
+	UserIDHeader = "X-User-ID"
+	// ScopesHeader holds the caller's scopes, separated by commas.
+	ScopesHeader = "X-User-Scopes"
+	// AdminScope lets a caller see and change every user's orders.
+	AdminScope = "*"
+)
+
+type OrderStatus string
+
+const (
+	StatusPending   OrderStatus = "pending"
+	StatusCompleted OrderStatus = "completed"
+	StatusCancelled OrderStatus = "cancelled"
+)
+
+type OrderItem struct {
+	ProductID uint    `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+type Order struct {
+	ID        uint        `json:"id"`
+	UserID    uint        `json:"user_id"`
+	Items     []OrderItem `json:"items"`
+	Total     float64     `json:"total"`
+	Status    OrderStatus `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// CreateOrderRequest holds the fields a client chooses when placing an order.
+// The server assigns the ID, makes the caller named in UserIDHeader the
+// owner, sets the status to pending, stamps the times, prices each item at
+// the product's current inventory price and computes the total from them.
+type CreateOrderRequest struct {
+	Items []CreateOrderItem `json:"items" binding:"required,min=1,dive"`
+}
+
+type CreateOrderItem struct {
+	ProductID uint `json:"product_id" binding:"required,gt=0"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type UpdateOrderStatusRequest struct {
+	Status OrderStatus `json:"status" binding:"required,oneof=pending completed cancelled"`
+}
+
+type OrderList struct {
+	Data []Order `json:"data"`
+}
+
+// Error is the body of every non-2xx response.
+type Error struct {
+	Error string `json:"error"`
+}
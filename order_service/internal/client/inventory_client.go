@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/observability/requestid"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/usecase"
+	"github.com/rrxshxd/assignment1_advProg2/proto/inventory"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type InventoryClient struct {
+	conn   *grpc.ClientConn
+	client inventory.InventoryServiceClient
+}
+
+// NewInventoryClient connects to the inventory service's gRPC API at addr.
+// opts are applied after the defaults, so they can replace the dialer or
+// credentials.
+func NewInventoryClient(addr string, opts ...grpc.DialOption) (*InventoryClient, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(requestid.UnaryClientInterceptor),
+	}, opts...)
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inventory service client: %w", err)
+	}
+
+	return &InventoryClient{conn: conn, client: inventory.NewInventoryServiceClient(conn)}, nil
+}
+
+// Check asks the inventory service's grpc.health.v1 service for its overall
+// status.
+func (c *InventoryClient) Check(ctx context.Context) error {
+	resp, err := grpc_health_v1.NewHealthClient(c.conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("inventory service is %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *InventoryClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetProducts loads the products in one call; IDs the inventory does not
+// know are left out of the result.
+func (c *InventoryClient) GetProducts(ctx context.Context, ids []uint) ([]*entity.Product, error) {
+	req := &inventory.BatchGetProductsRequest{Ids: make([]uint64, len(ids))}
+	for i, id := range ids {
+		req.Ids[i] = uint64(id)
+	}
+
+	resp, err := c.client.BatchGetProducts(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	products := make([]*entity.Product, len(resp.Products))
+	for i, product := range resp.Products {
+		products[i] = &entity.Product{ID: uint(product.Id), Price: product.Price}
+	}
+	return products, nil
+}
+
+// ReserveStock reserves the items' quantities in one call. A product without
+// enough stock is reported as usecase.ErrInsufficientStock.
+func (c *InventoryClient) ReserveStock(ctx context.Context, items []entity.OrderItem) error {
+	_, err := c.client.ReserveStock(ctx, &inventory.ReserveStockRequest{Items: stockItems(items)})
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return fmt.Errorf("%w: %s", usecase.ErrInsufficientStock, status.Convert(err).Message())
+		}
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+	return nil
+}
+
+func (c *InventoryClient) ReleaseStock(ctx context.Context, items []entity.OrderItem) error {
+	if _, err := c.client.ReleaseStock(ctx, &inventory.ReleaseStockRequest{Items: stockItems(items)}); err != nil {
+		return fmt.Errorf("failed to release stock: %w", err)
+	}
+	return nil
+}
+
+func stockItems(items []entity.OrderItem) []*inventory.StockItem {
+	result := make([]*inventory.StockItem, len(items))
+	for i, item := range items {
+		result[i] = &inventory.StockItem{ProductId: uint64(item.ProductID), Quantity: int32(item.Quantity)}
+	}
+	return result
+}
@@ -0,0 +1,187 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/usecase"
+	"github.com/rrxshxd/assignment1_advProg2/proto/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type OrderServer struct {
+	order.UnimplementedOrderServiceServer
+	orderUseCase *usecase.OrderUseCase
+}
+
+func NewOrderServer(orderUseCase *usecase.OrderUseCase) *OrderServer {
+	return &OrderServer{orderUseCase: orderUseCase}
+}
+
+// CreateOrder ignores the prices of the requested items; they are looked up
+// in the inventory like those of orders placed over HTTP.
+func (s *OrderServer) CreateOrder(ctx context.Context, req *order.CreateOrderRequest) (*order.Order, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one item is required")
+	}
+
+	newOrder := &entity.Order{
+		UserID: uint(req.UserId),
+		Status: entity.StatusPending,
+	}
+	for _, item := range req.Items {
+		if item.ProductId == 0 || item.Quantity <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "items need a product_id and a positive quantity")
+		}
+		newOrder.Items = append(newOrder.Items, entity.OrderItem{
+			ProductID: uint(item.ProductId),
+			Quantity:  int(item.Quantity),
+		})
+	}
+
+	if err := s.orderUseCase.CreateOrder(ctx, newOrder); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return orderToProto(newOrder), nil
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, req *order.GetOrderRequest) (*order.Order, error) {
+	o, err := s.orderUseCase.GetOrder(ctx, uint(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return orderToProto(o), nil
+}
+
+func (s *OrderServer) ListUserOrders(ctx context.Context, req *order.ListUserOrdersRequest) (*order.ListUserOrdersResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	orders, err := s.orderUseCase.GetUserOrders(ctx, uint(req.UserId))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	protoOrders := make([]*order.Order, len(orders))
+	for i, o := range orders {
+		protoOrders[i] = orderToProto(o)
+	}
+
+	return &order.ListUserOrdersResponse{Orders: protoOrders}, nil
+}
+
+func (s *OrderServer) UpdateOrderStatus(ctx context.Context, req *order.UpdateOrderStatusRequest) (*order.Order, error) {
+	newStatus, ok := statusFromProto[req.Status]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "status must be pending, completed or cancelled")
+	}
+
+	if err := s.orderUseCase.UpdateOrderStatus(ctx, uint(req.Id), newStatus); err != nil {
+		return nil, toStatus(err)
+	}
+
+	o, err := s.orderUseCase.GetOrder(ctx, uint(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return orderToProto(o), nil
+}
+
+func (s *OrderServer) WatchOrder(req *order.WatchOrderRequest, stream order.OrderService_WatchOrderServer) error {
+	// Subscribe before reading the current state so no update is lost between
+	// the two.
+	updates, unsubscribe := s.orderUseCase.WatchOrder(uint(req.Id))
+	defer func() { unsubscribe() }()
+
+	current, err := s.orderUseCase.GetOrder(stream.Context(), uint(req.Id))
+	if err != nil {
+		return toStatus(err)
+	}
+	if err := stream.Send(orderToProto(current)); err != nil {
+		return err
+	}
+
+	for !current.Status.IsFinal() {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case updated, ok := <-updates:
+			if !ok {
+				// The broker dropped us for falling behind, possibly along
+				// with the final update; resubscribe and reload the order.
+				updates, unsubscribe = s.orderUseCase.WatchOrder(uint(req.Id))
+				reloaded, err := s.orderUseCase.GetOrder(stream.Context(), uint(req.Id))
+				if err != nil {
+					return toStatus(err)
+				}
+				updated = reloaded
+			}
+			if updated.Status == current.Status {
+				continue
+			}
+			current = updated
+			if err := stream.Send(orderToProto(current)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var statusToProto = map[entity.OrderStatus]order.OrderStatus{
+	entity.StatusPending:   order.OrderStatus_ORDER_STATUS_PENDING,
+	entity.StatusCompleted: order.OrderStatus_ORDER_STATUS_COMPLETED,
+	entity.StatusCancelled: order.OrderStatus_ORDER_STATUS_CANCELLED,
+}
+
+var statusFromProto = map[order.OrderStatus]entity.OrderStatus{
+	order.OrderStatus_ORDER_STATUS_PENDING:   entity.StatusPending,
+	order.OrderStatus_ORDER_STATUS_COMPLETED: entity.StatusCompleted,
+	order.OrderStatus_ORDER_STATUS_CANCELLED: entity.StatusCancelled,
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrUnknownProduct):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrInsufficientStock):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrInventoryUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func orderToProto(o *entity.Order) *order.Order {
+	items := make([]*order.OrderItem, len(o.Items))
+	for i, item := range o.Items {
+		items[i] = &order.OrderItem{
+			ProductId: uint64(item.ProductID),
+			Quantity:  int32(item.Quantity),
+			Price:     item.Price,
+		}
+	}
+
+	return &order.Order{
+		Id:        uint64(o.ID),
+		UserId:    uint64(o.UserID),
+		Items:     items,
+		Total:     o.Total,
+		Status:    statusToProto[o.Status],
+		CreatedAt: timestamppb.New(o.CreatedAt),
+		UpdatedAt: timestamppb.New(o.UpdatedAt),
+	}
+}
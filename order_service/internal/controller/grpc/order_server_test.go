@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository/memory"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/usecase"
+	"github.com/rrxshxd/assignment1_advProg2/proto/order"
+	"google.golang.org/grpc"
+	"testing"
+	"time"
+)
+
+// watchStream records the orders WatchOrder sends, holding up the first send
+// until release is closed.
+type watchStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sending chan struct{}
+	release chan struct{}
+	sent    []*order.Order
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(o *order.Order) error {
+	if len(s.sent) == 0 {
+		close(s.sending)
+		<-s.release
+	}
+	s.sent = append(s.sent, o)
+	return nil
+}
+
+// fakeInventory prices every product at 1 and has unlimited stock.
+type fakeInventory struct{}
+
+func (fakeInventory) GetProducts(_ context.Context, ids []uint) ([]*entity.Product, error) {
+	products := make([]*entity.Product, len(ids))
+	for i, id := range ids {
+		products[i] = &entity.Product{ID: id, Price: 1}
+	}
+	return products, nil
+}
+
+func (fakeInventory) ReserveStock(context.Context, []entity.OrderItem) error {
+	return nil
+}
+
+func (fakeInventory) ReleaseStock(context.Context, []entity.OrderItem) error {
+	return nil
+}
+
+func TestWatchOrderSlowSubscriberSeesFinalStatus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	orderUseCase := usecase.NewOrderUseCase(memory.NewOrderRepository(), fakeInventory{})
+	created := &entity.Order{UserID: 1, Status: entity.StatusPending, Items: []entity.OrderItem{{ProductID: 1, Quantity: 1}}}
+	if err := orderUseCase.CreateOrder(ctx, created); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	stream := &watchStream{ctx: ctx, sending: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		done <- NewOrderServer(orderUseCase).WatchOrder(&order.WatchOrderRequest{Id: uint64(created.ID)}, stream)
+	}()
+
+	// Overflow the watcher's buffer while it is stuck sending the initial
+	// state, so the final update cannot be delivered.
+	<-stream.sending
+	for i := 0; i < 20; i++ {
+		if err := orderUseCase.UpdateOrderStatus(ctx, created.ID, entity.StatusPending); err != nil {
+			t.Fatalf("UpdateOrderStatus: %v", err)
+		}
+	}
+	if err := orderUseCase.UpdateOrderStatus(ctx, created.ID, entity.StatusCancelled); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	close(stream.release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("WatchOrder: %v", err)
+	}
+	last := stream.sent[len(stream.sent)-1]
+	if len(stream.sent) != 2 || last.Status != order.OrderStatus_ORDER_STATUS_CANCELLED {
+		t.Errorf("WatchOrder sent %v, want the pending order and then the cancellation", stream.sent)
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/dto"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const callerKey = "caller"
+
+// caller is the user the gateway authenticated for a request.
+type caller struct {
+	userID uint
+	admin  bool
+}
+
+// owns reports whether the caller may see and change order.
+func (c caller) owns(order *entity.Order) bool {
+	return c.admin || order.UserID == c.userID
+}
+
+// Identify reads the caller from the gateway's identity headers and rejects
+// requests without a valid user ID.
+func Identify() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := strconv.ParseUint(ctx.GetHeader(dto.UserIDHeader), 10, 32)
+		if err != nil || userID == 0 {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.Error{Error: "missing or invalid " + dto.UserIDHeader + " header"})
+			return
+		}
+
+		c := caller{userID: uint(userID)}
+		for _, scope := range strings.Split(ctx.GetHeader(dto.ScopesHeader), ",") {
+			if strings.TrimSpace(scope) == dto.AdminScope {
+				c.admin = true
+			}
+		}
+		ctx.Set(callerKey, c)
+
+		ctx.Next()
+	}
+}
+
+func callerFrom(ctx *gin.Context) caller {
+	return ctx.MustGet(callerKey).(caller)
+}
@@ -3,7 +3,9 @@ package controller
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/dto"
 	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
 	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/usecase"
 	"net/http"
 	"strconv"
@@ -18,62 +20,92 @@ func NewOrderController(orderUseCase *usecase.OrderUseCase) *OrderController {
 }
 
 func (c *OrderController) CreateOrder(ctx *gin.Context) {
-	var order entity.Order
-	if err := ctx.ShouldBindJSON(&order); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request dto.CreateOrderRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
 		return
 	}
 
-	if err := c.orderUseCase.CreateOrder(&order); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	order := orderFromRequest(request, callerFrom(ctx).userID)
+	if err := c.orderUseCase.CreateOrder(ctx.Request.Context(), order); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnknownProduct):
+			ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		case errors.Is(err, usecase.ErrInsufficientStock):
+			ctx.JSON(http.StatusConflict, dto.Error{Error: err.Error()})
+		case errors.Is(err, usecase.ErrInventoryUnavailable):
+			ctx.JSON(http.StatusBadGateway, dto.Error{Error: err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, order)
+	ctx.JSON(http.StatusCreated, orderResponse(order))
 }
 
 func (c *OrderController) GetOrder(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: "invalid order ID"})
 		return
 	}
 
-	order, err := c.orderUseCase.GetOrder(uint(id))
-	if err != nil {
-		if err == errors.New("order not found") {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	order, ok := c.ownedOrder(ctx, uint(id))
+	if !ok {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, order)
+	ctx.JSON(http.StatusOK, orderResponse(order))
+}
+
+// ownedOrder loads an order the caller may access. Other users' orders are
+// reported as missing so their IDs are not disclosed.
+func (c *OrderController) ownedOrder(ctx *gin.Context, id uint) (*entity.Order, bool) {
+	order, err := c.orderUseCase.GetOrder(ctx.Request.Context(), id)
+	if err == nil && !callerFrom(ctx).owns(order) {
+		err = repository.ErrOrderNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			ctx.JSON(http.StatusNotFound, dto.Error{Error: "order not found"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return nil, false
+	}
+	return order, true
 }
 
 func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: "invalid order ID"})
 		return
 	}
 
-	var request struct {
-		Status entity.OrderStatus `json:"status" binding:"required,oneof=pending completed cancelled"`
-	}
+	var request dto.UpdateOrderStatusRequest
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
 		return
 	}
 
-	if err := c.orderUseCase.UpdateOrderStatus(uint(id), request.Status); err != nil {
-		if err == errors.New("order not found") {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	if _, ok := c.ownedOrder(ctx, uint(id)); !ok {
+		return
+	}
+	// Customers may withdraw their orders; fulfilling them is up to admins.
+	if request.Status != dto.StatusCancelled && !callerFrom(ctx).admin {
+		ctx.JSON(http.StatusForbidden, dto.Error{Error: "only admins may set an order's status to " + string(request.Status)})
+		return
+	}
+
+	if err := c.orderUseCase.UpdateOrderStatus(ctx.Request.Context(), uint(id), entity.OrderStatus(request.Status)); err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			ctx.JSON(http.StatusNotFound, dto.Error{Error: "order not found"})
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
 		return
 	}
 
@@ -83,15 +115,52 @@ func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
 func (c *OrderController) GetUserOrders(ctx *gin.Context) {
 	userID, err := strconv.ParseUint(ctx.Query("user_id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		ctx.JSON(http.StatusBadRequest, dto.Error{Error: "invalid user ID"})
+		return
+	}
+	if caller := callerFrom(ctx); !caller.admin && uint(userID) != caller.userID {
+		ctx.JSON(http.StatusForbidden, dto.Error{Error: "only admins may list other users' orders"})
 		return
 	}
 
-	orders, err := c.orderUseCase.GetUserOrders(uint(userID))
+	orders, err := c.orderUseCase.GetUserOrders(ctx.Request.Context(), uint(userID))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": orders})
+	response := dto.OrderList{Data: make([]dto.Order, 0, len(orders))}
+	for _, order := range orders {
+		response.Data = append(response.Data, orderResponse(order))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func orderFromRequest(request dto.CreateOrderRequest, userID uint) *entity.Order {
+	order := &entity.Order{
+		UserID: userID,
+		Items:  make([]entity.OrderItem, 0, len(request.Items)),
+		Status: entity.StatusPending,
+	}
+	for _, item := range request.Items {
+		order.Items = append(order.Items, entity.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	return order
+}
+
+func orderResponse(order *entity.Order) dto.Order {
+	response := dto.Order{
+		ID:        order.ID,
+		UserID:    order.UserID,
+		Items:     make([]dto.OrderItem, 0, len(order.Items)),
+		Total:     order.Total,
+		Status:    dto.OrderStatus(order.Status),
+		CreatedAt: order.CreatedAt,
+		UpdatedAt: order.UpdatedAt,
+	}
+	for _, item := range order.Items {
+		response.Items = append(response.Items, dto.OrderItem(item))
+	}
+	return response
 }
@@ -10,6 +10,11 @@ const (
 	StatusCancelled OrderStatus = "cancelled"
 )
 
+// IsFinal reports whether no further status changes are expected.
+func (s OrderStatus) IsFinal() bool {
+	return s == StatusCompleted || s == StatusCancelled
+}
+
 type OrderItem struct {
 	ProductID uint    `json:"product_id"`
 	Quantity  int     `json:"quantity"`
@@ -0,0 +1,7 @@
+package entity
+
+// Product is what an order needs to know about an inventory product.
+type Product struct {
+	ID    uint
+	Price float64
+}
@@ -0,0 +1,107 @@
+// Package openapi describes the order HTTP API as an OpenAPI 3 document
+// generated from the dto types.
+package openapi
+
+import (
+	"context"
+	"github.com/getkin/kin-openapi/openapi3"
+	sharedopenapi "github.com/rrxshxd/assignment1_advProg2/openapi"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/dto"
+	"net/http"
+)
+
+// Spec returns the validated OpenAPI document of the order HTTP API.
+func Spec(ctx context.Context) (*openapi3.T, error) {
+	schemas, err := sharedopenapi.GenerateSchemas(map[string]any{
+		"Order":                    dto.Order{},
+		"OrderList":                dto.OrderList{},
+		"CreateOrderRequest":       dto.CreateOrderRequest{},
+		"UpdateOrderStatusRequest": dto.UpdateOrderStatusRequest{},
+		"Error":                    dto.Error{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orderID := sharedopenapi.IDParameter("Order ID")
+	userID := openapi3.NewQueryParameter("user_id").
+		WithDescription("Owner of the orders").
+		WithRequired(true).
+		WithSchema(openapi3.NewIntegerSchema().WithMin(1))
+	invalid := sharedopenapi.JSONResponse("Invalid request", "Error")
+	notFound := sharedopenapi.JSONResponse("Order not found", "Error")
+	failed := sharedopenapi.JSONResponse("Internal error", "Error")
+	unidentified := sharedopenapi.JSONResponse("The "+dto.UserIDHeader+" header is missing or invalid", "Error")
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title: "Order service",
+			Description: "Every route acts for the caller the gateway names in the " + dto.UserIDHeader + " and " + dto.ScopesHeader +
+				" headers. Callers without the admin scope only see their own orders and may only cancel them.",
+			Version: "1.0.0",
+		},
+		Components: &openapi3.Components{Schemas: schemas},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/orders", &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					OperationID: "listUserOrders",
+					Summary:     "List the orders of a user, newest first",
+					Parameters:  openapi3.Parameters{{Value: userID}},
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusOK:                  sharedopenapi.JSONResponse("The user's orders", "OrderList"),
+						http.StatusBadRequest:          invalid,
+						http.StatusUnauthorized:        unidentified,
+						http.StatusForbidden:           sharedopenapi.JSONResponse("Another user's orders were requested without the admin scope", "Error"),
+						http.StatusInternalServerError: failed,
+					}),
+				},
+				Post: &openapi3.Operation{
+					OperationID: "createOrder",
+					Summary:     "Create an order, pricing its items and reserving their stock in the inventory",
+					RequestBody: sharedopenapi.JSONBody("CreateOrderRequest"),
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusCreated:             sharedopenapi.JSONResponse("The created order", "Order"),
+						http.StatusBadRequest:          sharedopenapi.JSONResponse("Invalid request or unknown product", "Error"),
+						http.StatusUnauthorized:        unidentified,
+						http.StatusConflict:            sharedopenapi.JSONResponse("A product does not have enough stock", "Error"),
+						http.StatusBadGateway:          sharedopenapi.JSONResponse("The inventory could not be reached", "Error"),
+						http.StatusInternalServerError: failed,
+					}),
+				},
+			}),
+			openapi3.WithPath("/orders/{id}", &openapi3.PathItem{
+				Parameters: openapi3.Parameters{orderID},
+				Get: &openapi3.Operation{
+					OperationID: "getOrder",
+					Summary:     "Get an order; other users' orders are reported as not found",
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusOK:                  sharedopenapi.JSONResponse("The order", "Order"),
+						http.StatusBadRequest:          invalid,
+						http.StatusUnauthorized:        unidentified,
+						http.StatusNotFound:            notFound,
+						http.StatusInternalServerError: failed,
+					}),
+				},
+				Patch: &openapi3.Operation{
+					OperationID: "updateOrderStatus",
+					Summary:     "Change the status of an order",
+					RequestBody: sharedopenapi.JSONBody("UpdateOrderStatusRequest"),
+					Responses: sharedopenapi.Responses(map[int]*openapi3.ResponseRef{
+						http.StatusNoContent:           sharedopenapi.EmptyResponse("The status was changed"),
+						http.StatusBadRequest:          invalid,
+						http.StatusUnauthorized:        unidentified,
+						http.StatusForbidden:           sharedopenapi.JSONResponse("A status other than cancelled was set without the admin scope", "Error"),
+						http.StatusNotFound:            notFound,
+						http.StatusInternalServerError: failed,
+					}),
+				},
+			}),
+		),
+	}
+
+	if err := sharedopenapi.Resolve(ctx, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
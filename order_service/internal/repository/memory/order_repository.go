@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
+	"sort"
+	"sync"
+	"time"
+)
+
+type orderRepository struct {
+	mu     sync.RWMutex
+	orders map[uint]entity.Order
+	nextID uint
+}
+
+// NewOrderRepository returns an empty store that behaves like the Postgres
+// repository: IDs start at 1 and FindByUserID returns the newest order first.
+func NewOrderRepository() repository.OrderRepository {
+	return &orderRepository{orders: make(map[uint]entity.Order), nextID: 1}
+}
+
+func (r *orderRepository) Create(ctx context.Context, order *entity.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order.ID = r.nextID
+	r.nextID++
+	r.orders[order.ID] = copyOrder(*order)
+
+	return nil
+}
+
+func (r *orderRepository) FindByID(ctx context.Context, id uint) (*entity.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", repository.ErrOrderNotFound, id)
+	}
+
+	found := copyOrder(order)
+	return &found, nil
+}
+
+func (r *orderRepository) UpdateStatus(ctx context.Context, id uint, status entity.OrderStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return fmt.Errorf("%w: %d", repository.ErrOrderNotFound, id)
+	}
+
+	order.Status = status
+	order.UpdatedAt = time.Now()
+	r.orders[id] = order
+
+	return nil
+}
+
+func (r *orderRepository) FindByUserID(ctx context.Context, userID uint) ([]*entity.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orders := make([]*entity.Order, 0)
+	for _, order := range r.orders {
+		if order.UserID == userID {
+			found := copyOrder(order)
+			orders = append(orders, &found)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.After(orders[j].CreatedAt)
+		}
+		return orders[i].ID > orders[j].ID
+	})
+
+	return orders, nil
+}
+
+// copyOrder keeps callers from mutating stored items through a shared slice.
+func copyOrder(order entity.Order) entity.Order {
+	order.Items = append([]entity.OrderItem(nil), order.Items...)
+	return order
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestOrderRepository(t *testing.T) {
+	repositorytest.TestOrderRepository(t, func(*testing.T) repository.OrderRepository {
+		return NewOrderRepository()
+	})
+}
@@ -1,10 +1,16 @@
 package repository
 
-import "github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
 
 type OrderRepository interface {
-	Create(order *entity.Order) error
-	FindByID(id uint) (*entity.Order, error)
-	UpdateStatus(id uint, status entity.OrderStatus) error
-	FindByUserID(userID uint) ([]*entity.Order, error)
+	Create(ctx context.Context, order *entity.Order) error
+	FindByID(ctx context.Context, id uint) (*entity.Order, error)
+	UpdateStatus(ctx context.Context, id uint, status entity.OrderStatus) error
+	FindByUserID(ctx context.Context, userID uint) ([]*entity.Order, error)
 }
@@ -1,27 +1,36 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
-	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/database"
+	"github.com/rrxshxd/assignment1_advProg2/observability/tracing"
 	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
 	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
 )
 
+var tracer = tracing.NewDBTracer("github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository/postgres")
+
 type orderRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
-func NewOrderRepository(db *sql.DB) repository.OrderRepository {
+func NewOrderRepository(db *database.DB) repository.OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(order *entity.Order) error {
-	tx, err := r.db.Begin()
+func (r *orderRepository) Create(ctx context.Context, order *entity.Order) (err error) {
+	ctx, span := tracer.Start(ctx, "OrderRepository.Create", "INSERT", "orders")
+	defer func() { tracer.End(ctx, span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	err = tx.QueryRow(
+	err = tx.QueryRowContext(
+		ctx,
 		`INSERT INTO orders (user_id, total, status, created_at, updated_at) 
          VALUES ($1, $2, $3, $4, $5) RETURNING id`,
 		order.UserID, order.Total, order.Status, order.CreatedAt, order.UpdatedAt,
@@ -33,7 +42,8 @@ func (r *orderRepository) Create(order *entity.Order) error {
 	}
 
 	for _, item := range order.Items {
-		_, err = tx.Exec(
+		_, err = tx.ExecContext(
+			ctx,
 			`INSERT INTO order_items (order_id, product_id, quantity, price) 
              VALUES ($1, $2, $3, $4)`,
 			order.ID, item.ProductID, item.Quantity, item.Price,
@@ -47,7 +57,10 @@ func (r *orderRepository) Create(order *entity.Order) error {
 	return tx.Commit()
 }
 
-func (r *orderRepository) FindByID(id uint) (*entity.Order, error) {
+func (r *orderRepository) FindByID(ctx context.Context, id uint) (_ *entity.Order, err error) {
+	ctx, span := tracer.Start(ctx, "OrderRepository.FindByID", "SELECT", "orders")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `
         SELECT o.id, o.user_id, o.total, o.status, o.created_at, o.updated_at,
                oi.product_id, oi.quantity, oi.price
@@ -56,7 +69,7 @@ func (r *orderRepository) FindByID(id uint) (*entity.Order, error) {
         WHERE o.id = $1
     `
 
-	rows, err := r.db.Query(query, id)
+	rows, err := r.db.QueryContext(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
@@ -81,29 +94,48 @@ func (r *orderRepository) FindByID(id uint) (*entity.Order, error) {
 	}
 
 	if !orderFound {
-		return nil, errors.New("order not found")
+		return nil, fmt.Errorf("%w: %d", repository.ErrOrderNotFound, id)
 	}
 
 	order.Items = items
 	return &order, nil
 }
 
-func (r *orderRepository) FindByUserID(userID uint) ([]*entity.Order, error) {
+func (r *orderRepository) FindByUserID(ctx context.Context, userID uint) (_ []*entity.Order, err error) {
+	ctx, span := tracer.Start(ctx, "OrderRepository.FindByUserID", "SELECT", "orders")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `
         SELECT o.id, o.user_id, o.total, o.status, o.created_at, o.updated_at,
                oi.product_id, oi.quantity, oi.price
         FROM orders o
         LEFT JOIN order_items oi ON o.id = oi.order_id
         WHERE o.user_id = $1
-        ORDER BY o.created_at DESC
+        ORDER BY o.created_at DESC, o.id DESC
     `
 
-	rows, err := r.db.Query(query, userID)
+	var orders []*entity.Order
+	err = r.db.Read(ctx, func(db *sql.DB) error {
+		orders, err = queryUserOrders(ctx, db, query, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// queryUserOrders folds the joined rows into orders, keeping the order in
+// which the query returned them.
+func queryUserOrders(ctx context.Context, db *sql.DB, query string, userID uint) ([]*entity.Order, error) {
+	rows, err := db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	orders := make([]*entity.Order, 0)
 	ordersMap := make(map[uint]*entity.Order)
 	for rows.Next() {
 		var orderID uint
@@ -125,21 +157,33 @@ func (r *orderRepository) FindByUserID(userID uint) ([]*entity.Order, error) {
 			order.ID = orderID
 			order.Items = []entity.OrderItem{item}
 			ordersMap[orderID] = &order
+			orders = append(orders, &order)
 		}
 	}
 
-	orders := make([]*entity.Order, 0, len(ordersMap))
-	for _, order := range ordersMap {
-		orders = append(orders, order)
-	}
-
-	return orders, nil
+	return orders, rows.Err()
 }
 
-func (r *orderRepository) UpdateStatus(id uint, status entity.OrderStatus) error {
-	_, err := r.db.Exec(
+func (r *orderRepository) UpdateStatus(ctx context.Context, id uint, status entity.OrderStatus) (err error) {
+	ctx, span := tracer.Start(ctx, "OrderRepository.UpdateStatus", "UPDATE", "orders")
+	defer func() { tracer.End(ctx, span, err) }()
+
+	result, err := r.db.ExecContext(
+		ctx,
 		`UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2`,
 		status, id,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d", repository.ErrOrderNotFound, id)
+	}
+
+	return nil
 }
@@ -0,0 +1,18 @@
+//go:build integration
+
+package postgres
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/database"
+	"github.com/rrxshxd/assignment1_advProg2/database/databasetest"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestOrderRepository(t *testing.T) {
+	repositorytest.TestOrderRepository(t, func(t *testing.T) repository.OrderRepository {
+		databasetest.Truncate(t, testDB, "orders, order_items")
+		return NewOrderRepository(database.NewDB(testDB, nil, nil))
+	})
+}
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+
+CREATE TABLE orders (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER NOT NULL,
+    total      NUMERIC(12, 2) NOT NULL,
+    status     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE order_items (
+    order_id   INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL,
+    quantity   INTEGER NOT NULL,
+    price      NUMERIC(12, 2) NOT NULL
+);
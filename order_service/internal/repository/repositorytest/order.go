@@ -0,0 +1,227 @@
+// Package repositorytest holds contract tests that every repository
+// implementation must pass, whatever storage it uses.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// OrderFactory returns an empty repository. It is called once per subtest.
+type OrderFactory func(t *testing.T) repository.OrderRepository
+
+// TestOrderRepository runs the OrderRepository contract against the
+// repositories returned by newRepo.
+func TestOrderRepository(t *testing.T, newRepo OrderFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.OrderRepository)
+	}{
+		{"CreateAndFind", testOrderCreateAndFind},
+		{"FindMissing", testOrderFindMissing},
+		{"UpdateStatus", testOrderUpdateStatus},
+		{"UpdateStatusMissing", testOrderUpdateStatusMissing},
+		{"FindByUserID", testOrderFindByUserID},
+		{"FindByUserIDEmpty", testOrderFindByUserIDEmpty},
+		{"ConcurrentCreate", testOrderConcurrentCreate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+// now is truncated to the microsecond resolution Postgres stores.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func newOrder(userID uint, createdAt time.Time, items ...entity.OrderItem) entity.Order {
+	order := entity.Order{UserID: userID, Items: items, Status: entity.StatusPending, CreatedAt: createdAt, UpdatedAt: createdAt}
+	for _, item := range items {
+		order.Total += item.Price * float64(item.Quantity)
+	}
+	return order
+}
+
+func createOrder(t *testing.T, repo repository.OrderRepository, order entity.Order) *entity.Order {
+	t.Helper()
+	if err := repo.Create(context.Background(), &order); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return &order
+}
+
+// sortedItems orders items by product so they compare equal regardless of
+// the order storage returns them in.
+func sortedItems(items []entity.OrderItem) []entity.OrderItem {
+	sorted := append([]entity.OrderItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+	return sorted
+}
+
+func equalItems(a, b []entity.OrderItem) bool {
+	a, b = sortedItems(a), sortedItems(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testOrderCreateAndFind(t *testing.T, repo repository.OrderRepository) {
+	createdAt := now()
+	first := createOrder(t, repo, newOrder(7, createdAt,
+		entity.OrderItem{ProductID: 2, Quantity: 1, Price: 10},
+		entity.OrderItem{ProductID: 1, Quantity: 3, Price: 2.5},
+	))
+	second := createOrder(t, repo, newOrder(7, createdAt, entity.OrderItem{ProductID: 1, Quantity: 1, Price: 2.5}))
+
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("IDs = %d, %d, want increasing non-zero IDs", first.ID, second.ID)
+	}
+
+	got, err := repo.FindByID(context.Background(), first.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.ID != first.ID || got.UserID != 7 || got.Total != 17.5 || got.Status != entity.StatusPending {
+		t.Errorf("FindByID = %+v, want the created order", got)
+	}
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, createdAt)
+	}
+	if !equalItems(got.Items, first.Items) {
+		t.Errorf("Items = %+v, want %+v", got.Items, first.Items)
+	}
+}
+
+func testOrderFindMissing(t *testing.T, repo repository.OrderRepository) {
+	if _, err := repo.FindByID(context.Background(), 404); !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("FindByID(404) error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func testOrderUpdateStatus(t *testing.T, repo repository.OrderRepository) {
+	ctx := context.Background()
+	createdAt := now().Add(-time.Hour)
+	order := createOrder(t, repo, newOrder(1, createdAt, entity.OrderItem{ProductID: 1, Quantity: 1, Price: 1}))
+
+	if err := repo.UpdateStatus(ctx, order.ID, entity.StatusCompleted); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Status != entity.StatusCompleted {
+		t.Errorf("Status = %q, want %q", got.Status, entity.StatusCompleted)
+	}
+	if !got.UpdatedAt.After(createdAt) {
+		t.Errorf("UpdatedAt = %v, want it moved past %v", got.UpdatedAt, createdAt)
+	}
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("UpdateStatus changed CreatedAt from %v to %v", createdAt, got.CreatedAt)
+	}
+}
+
+func testOrderUpdateStatusMissing(t *testing.T, repo repository.OrderRepository) {
+	err := repo.UpdateStatus(context.Background(), 404, entity.StatusCancelled)
+	if !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("UpdateStatus(404) error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func testOrderFindByUserID(t *testing.T, repo repository.OrderRepository) {
+	base := now()
+	item := entity.OrderItem{ProductID: 1, Quantity: 1, Price: 1}
+	old := createOrder(t, repo, newOrder(1, base.Add(-time.Hour), item))
+	tiedFirst := createOrder(t, repo, newOrder(1, base, item))
+	createOrder(t, repo, newOrder(2, base.Add(time.Hour), item))
+	tiedSecond := createOrder(t, repo, newOrder(1, base, item, entity.OrderItem{ProductID: 2, Quantity: 2, Price: 3}))
+
+	orders, err := repo.FindByUserID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindByUserID: %v", err)
+	}
+
+	want := []uint{tiedSecond.ID, tiedFirst.ID, old.ID}
+	if len(orders) != len(want) {
+		t.Fatalf("FindByUserID returned %d orders, want %d", len(orders), len(want))
+	}
+	for i, order := range orders {
+		if order.ID != want[i] {
+			t.Errorf("orders[%d].ID = %d, want %d (newest first, ties by highest ID)", i, order.ID, want[i])
+		}
+		if order.UserID != 1 {
+			t.Errorf("orders[%d] belongs to user %d", i, order.UserID)
+		}
+	}
+	if !equalItems(orders[0].Items, tiedSecond.Items) {
+		t.Errorf("orders[0].Items = %+v, want %+v", orders[0].Items, tiedSecond.Items)
+	}
+}
+
+func testOrderFindByUserIDEmpty(t *testing.T, repo repository.OrderRepository) {
+	orders, err := repo.FindByUserID(context.Background(), 404)
+	if err != nil {
+		t.Fatalf("FindByUserID: %v", err)
+	}
+	if orders == nil || len(orders) != 0 {
+		t.Errorf("FindByUserID(404) = %#v, want an empty non-nil slice", orders)
+	}
+}
+
+func testOrderConcurrentCreate(t *testing.T, repo repository.OrderRepository) {
+	const n = 20
+	ids := make(chan uint, n)
+	errs := make(chan error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order := newOrder(1, now(), entity.OrderItem{ProductID: 1, Quantity: 1, Price: 1})
+			if err := repo.Create(context.Background(), &order); err != nil {
+				errs <- err
+				return
+			}
+			ids <- order.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Create: %v", err)
+	}
+	seen := make(map[uint]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("ID %d handed out twice", id)
+		}
+		seen[id] = true
+	}
+
+	orders, err := repo.FindByUserID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindByUserID: %v", err)
+	}
+	if len(orders) != len(seen) {
+		t.Errorf("FindByUserID returned %d orders, want %d", len(orders), len(seen))
+	}
+}
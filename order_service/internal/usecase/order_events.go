@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"sync"
+)
+
+// orderBroker fans out order updates to in-process subscribers. A subscriber
+// whose buffer is full is dropped and its channel closed rather than blocking
+// publishers, so it can tell that it missed updates and reload the order.
+type orderBroker struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan *entity.Order]struct{}
+}
+
+func newOrderBroker() *orderBroker {
+	return &orderBroker{subscribers: make(map[uint]map[chan *entity.Order]struct{})}
+}
+
+func (b *orderBroker) subscribe(orderID uint) (<-chan *entity.Order, func()) {
+	ch := make(chan *entity.Order, 8)
+
+	b.mu.Lock()
+	if b.subscribers[orderID] == nil {
+		b.subscribers[orderID] = make(map[chan *entity.Order]struct{})
+	}
+	b.subscribers[orderID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[orderID][ch]; !ok {
+			return
+		}
+		delete(b.subscribers[orderID], ch)
+		if len(b.subscribers[orderID]) == 0 {
+			delete(b.subscribers, orderID)
+		}
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *orderBroker) publish(order *entity.Order) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[order.ID] {
+		select {
+		case ch <- order:
+		default:
+			delete(b.subscribers[order.ID], ch)
+			close(ch)
+		}
+	}
+	if len(b.subscribers[order.ID]) == 0 {
+		delete(b.subscribers, order.ID)
+	}
+}
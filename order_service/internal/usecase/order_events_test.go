@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"testing"
+)
+
+func TestOrderBrokerDropsSlowSubscriber(t *testing.T) {
+	broker := newOrderBroker()
+	slow, unsubscribeSlow := broker.subscribe(1)
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := broker.subscribe(1)
+	defer unsubscribeFast()
+
+	for i := 0; i < 12; i++ {
+		broker.publish(&entity.Order{ID: 1, Status: entity.StatusPending})
+		<-fast
+	}
+	broker.publish(&entity.Order{ID: 1, Status: entity.StatusCompleted})
+	if got := <-fast; got.Status != entity.StatusCompleted {
+		t.Errorf("fast subscriber received %s, want the final update", got.Status)
+	}
+
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != cap(fast) {
+		t.Errorf("slow subscriber received %d updates before its channel closed, want the %d buffered ones", received, cap(fast))
+	}
+
+	unsubscribeSlow()
+	broker.publish(&entity.Order{ID: 1, Status: entity.StatusCompleted})
+	if got := <-fast; got.Status != entity.StatusCompleted {
+		t.Errorf("fast subscriber received %s after the slow one was dropped", got.Status)
+	}
+}
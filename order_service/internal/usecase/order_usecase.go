@@ -1,30 +1,152 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
 	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/metrics"
 	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
+	"time"
 )
 
+var (
+	ErrUnknownProduct       = errors.New("unknown product")
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrInventoryUnavailable = errors.New("inventory unavailable")
+)
+
+type Inventory interface {
+	// GetProducts returns the products that exist among ids.
+	GetProducts(ctx context.Context, ids []uint) ([]*entity.Product, error)
+	// ReserveStock takes the items' quantities out of stock, all or nothing.
+	// It fails with an error wrapping ErrInsufficientStock if any product
+	// has too little left.
+	ReserveStock(ctx context.Context, items []entity.OrderItem) error
+	// ReleaseStock puts reserved quantities back into stock.
+	ReleaseStock(ctx context.Context, items []entity.OrderItem) error
+}
+
 type OrderUseCase struct {
 	orderRepo repository.OrderRepository
+	inventory Inventory
+	broker    *orderBroker
+}
+
+func NewOrderUseCase(orderRepo repository.OrderRepository, inventory Inventory) *OrderUseCase {
+	return &OrderUseCase{orderRepo: orderRepo, inventory: inventory, broker: newOrderBroker()}
+}
+
+// CreateOrder stores a new order. The ID and timestamps are always assigned
+// here, whatever the caller set, and each item is priced at the product's
+// current price in the inventory, from which the total is computed. The
+// items' stock is reserved before the order is stored and released again if
+// storing it fails.
+func (uc *OrderUseCase) CreateOrder(ctx context.Context, order *entity.Order) error {
+	if err := uc.price(ctx, order); err != nil {
+		return err
+	}
+
+	if err := uc.inventory.ReserveStock(ctx, order.Items); err != nil {
+		if errors.Is(err, ErrInsufficientStock) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInventoryUnavailable, err)
+	}
+
+	now := time.Now()
+	order.ID = 0
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	if err := uc.orderRepo.Create(ctx, order); err != nil {
+		uc.release(ctx, order)
+		return err
+	}
+
+	metrics.OrdersCreated.WithLabelValues(string(order.Status)).Inc()
+	logging.FromContext(ctx).InfoContext(ctx, "order created", "order_id", order.ID, "user_id", order.UserID, "status", order.Status)
+	return nil
+}
+
+func (uc *OrderUseCase) price(ctx context.Context, order *entity.Order) error {
+	ids := make([]uint, len(order.Items))
+	for i, item := range order.Items {
+		ids[i] = item.ProductID
+	}
+
+	products, err := uc.inventory.GetProducts(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInventoryUnavailable, err)
+	}
+	prices := make(map[uint]float64, len(products))
+	for _, product := range products {
+		prices[product.ID] = product.Price
+	}
+
+	order.Total = 0
+	for i := range order.Items {
+		item := &order.Items[i]
+		price, ok := prices[item.ProductID]
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownProduct, item.ProductID)
+		}
+		item.Price = price
+		order.Total += price * float64(item.Quantity)
+	}
+	return nil
 }
 
-func NewOrderUseCase(orderRepo repository.OrderRepository) *OrderUseCase {
-	return &OrderUseCase{orderRepo: orderRepo}
+func (uc *OrderUseCase) GetOrder(ctx context.Context, id uint) (*entity.Order, error) {
+	return uc.orderRepo.FindByID(ctx, id)
 }
 
-func (uc *OrderUseCase) CreateOrder(order *entity.Order) error {
-	return uc.orderRepo.Create(order)
+// UpdateOrderStatus sets an order's status. Cancelling a pending order
+// releases its reserved stock; completed orders keep theirs.
+func (uc *OrderUseCase) UpdateOrderStatus(ctx context.Context, id uint, status entity.OrderStatus) error {
+	var previous *entity.Order
+	if status == entity.StatusCancelled {
+		var err error
+		if previous, err = uc.orderRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.orderRepo.UpdateStatus(ctx, id, status); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "order status updated", "order_id", id, "status", status)
+
+	if previous != nil && previous.Status == entity.StatusPending {
+		uc.release(ctx, previous)
+	}
+
+	if order, err := uc.orderRepo.FindByID(ctx, id); err == nil {
+		uc.broker.publish(order)
+	}
+
+	return nil
 }
 
-func (uc *OrderUseCase) GetOrder(id uint) (*entity.Order, error) {
-	return uc.orderRepo.FindByID(id)
+// release returns an order's stock to the inventory. A failure is only
+// logged: the order's own change has already been made, and the inventory
+// refuses to release more than was reserved, so an operator can safely retry.
+func (uc *OrderUseCase) release(ctx context.Context, order *entity.Order) {
+	ctx = context.WithoutCancel(ctx)
+	if err := uc.inventory.ReleaseStock(ctx, order.Items); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to release order stock", "order_id", order.ID, "error", err)
+	}
 }
 
-func (uc *OrderUseCase) UpdateOrderStatus(id uint, status entity.OrderStatus) error {
-	return uc.orderRepo.UpdateStatus(id, status)
+func (uc *OrderUseCase) GetUserOrders(ctx context.Context, userID uint) ([]*entity.Order, error) {
+	return uc.orderRepo.FindByUserID(ctx, userID)
 }
 
-func (uc *OrderUseCase) GetUserOrders(userID uint) ([]*entity.Order, error) {
-	return uc.orderRepo.FindByUserID(userID)
+// WatchOrder subscribes to updates of an order made through this instance.
+// The channel is closed early if the subscriber falls behind, after which
+// only GetOrder knows the current state. The returned function must be
+// called to release the subscription.
+func (uc *OrderUseCase) WatchOrder(id uint) (<-chan *entity.Order, func()) {
+	return uc.broker.subscribe(id)
 }
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/order_service/internal/repository/memory"
+	"reflect"
+	"testing"
+)
+
+// fakeInventory prices products from prices. Stock is only limited for the
+// products in stock, if it is set.
+type fakeInventory struct {
+	prices     map[uint]float64
+	stock      map[uint]int
+	err        error
+	reserveErr error
+	released   []entity.OrderItem
+}
+
+func (f *fakeInventory) GetProducts(_ context.Context, ids []uint) ([]*entity.Product, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var products []*entity.Product
+	for _, id := range ids {
+		if price, ok := f.prices[id]; ok {
+			products = append(products, &entity.Product{ID: id, Price: price})
+		}
+	}
+	return products, nil
+}
+
+func (f *fakeInventory) ReserveStock(_ context.Context, items []entity.OrderItem) error {
+	if f.reserveErr != nil {
+		return f.reserveErr
+	}
+	for _, item := range items {
+		if stock, limited := f.stock[item.ProductID]; limited && stock < item.Quantity {
+			return fmt.Errorf("%w for product %d", ErrInsufficientStock, item.ProductID)
+		}
+	}
+	for _, item := range items {
+		if _, limited := f.stock[item.ProductID]; limited {
+			f.stock[item.ProductID] -= item.Quantity
+		}
+	}
+	return nil
+}
+
+func (f *fakeInventory) ReleaseStock(_ context.Context, items []entity.OrderItem) error {
+	f.released = append(f.released, items...)
+	for _, item := range items {
+		if _, limited := f.stock[item.ProductID]; limited {
+			f.stock[item.ProductID] += item.Quantity
+		}
+	}
+	return nil
+}
+
+// failingOrders fails to store any order.
+type failingOrders struct {
+	repository.OrderRepository
+}
+
+func (failingOrders) Create(context.Context, *entity.Order) error {
+	return errors.New("connection refused")
+}
+
+func TestCreateOrderPricesItemsFromInventory(t *testing.T) {
+	ctx := context.Background()
+	orders := memory.NewOrderRepository()
+	uc := NewOrderUseCase(orders, &fakeInventory{prices: map[uint]float64{1: 2.5, 2: 10}})
+
+	order := &entity.Order{
+		UserID: 7,
+		Status: entity.StatusPending,
+		Total:  1,
+		Items: []entity.OrderItem{
+			{ProductID: 1, Quantity: 4, Price: 0.01},
+			{ProductID: 2, Quantity: 1},
+		},
+	}
+	if err := uc.CreateOrder(ctx, order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if order.Items[0].Price != 2.5 || order.Items[1].Price != 10 || order.Total != 20 {
+		t.Errorf("CreateOrder priced %+v at %v, want 2.5 and 10 for a total of 20", order.Items, order.Total)
+	}
+
+	stored, err := orders.FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.Total != 20 || stored.Items[0].Price != 2.5 {
+		t.Errorf("stored order = %+v, want the inventory prices", stored)
+	}
+}
+
+func TestCreateOrderRejectsUnpricedOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		products *fakeInventory
+		wantErr  error
+	}{
+		{name: "unknown product", products: &fakeInventory{prices: map[uint]float64{1: 2.5}}, wantErr: ErrUnknownProduct},
+		{name: "inventory down", products: &fakeInventory{err: errors.New("connection refused")}, wantErr: ErrInventoryUnavailable},
+		{
+			name:     "insufficient stock",
+			products: &fakeInventory{prices: map[uint]float64{1: 2.5, 2: 10}, stock: map[uint]int{2: 0}},
+			wantErr:  ErrInsufficientStock,
+		},
+		{
+			name:     "reservation failed",
+			products: &fakeInventory{prices: map[uint]float64{1: 2.5, 2: 10}, reserveErr: errors.New("connection reset")},
+			wantErr:  ErrInventoryUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			orders := memory.NewOrderRepository()
+			uc := NewOrderUseCase(orders, tt.products)
+
+			order := &entity.Order{UserID: 7, Status: entity.StatusPending, Items: []entity.OrderItem{
+				{ProductID: 1, Quantity: 1},
+				{ProductID: 2, Quantity: 1},
+			}}
+			if err := uc.CreateOrder(ctx, order); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateOrder = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := orders.FindByID(ctx, 1); !errors.Is(err, repository.ErrOrderNotFound) {
+				t.Errorf("FindByID after a rejected order = %v, want ErrOrderNotFound", err)
+			}
+		})
+	}
+}
+
+func TestCreateOrderReleasesStockIfStoringFails(t *testing.T) {
+	inventory := &fakeInventory{prices: map[uint]float64{1: 2.5}, stock: map[uint]int{1: 5}}
+	uc := NewOrderUseCase(failingOrders{memory.NewOrderRepository()}, inventory)
+
+	items := []entity.OrderItem{{ProductID: 1, Quantity: 2}}
+	if err := uc.CreateOrder(context.Background(), &entity.Order{UserID: 7, Status: entity.StatusPending, Items: items}); err == nil {
+		t.Fatal("CreateOrder = nil error, want the repository failure")
+	}
+	if inventory.stock[1] != 5 || len(inventory.released) != 1 {
+		t.Errorf("stock = %d after releasing %+v, want the reservation of 2 undone", inventory.stock[1], inventory.released)
+	}
+}
+
+func TestUpdateOrderStatusReleasesStockOfCancelledOrders(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []entity.OrderStatus
+		wantStock int
+	}{
+		{name: "pending", statuses: nil, wantStock: 3},
+		{name: "cancelled", statuses: []entity.OrderStatus{entity.StatusCancelled}, wantStock: 5},
+		{name: "cancelled twice", statuses: []entity.OrderStatus{entity.StatusCancelled, entity.StatusCancelled}, wantStock: 5},
+		{name: "completed", statuses: []entity.OrderStatus{entity.StatusCompleted}, wantStock: 3},
+		{name: "cancelled after completion", statuses: []entity.OrderStatus{entity.StatusCompleted, entity.StatusCancelled}, wantStock: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			inventory := &fakeInventory{prices: map[uint]float64{1: 2.5}, stock: map[uint]int{1: 5}}
+			uc := NewOrderUseCase(memory.NewOrderRepository(), inventory)
+
+			items := []entity.OrderItem{{ProductID: 1, Quantity: 2}}
+			order := &entity.Order{UserID: 7, Status: entity.StatusPending, Items: items}
+			if err := uc.CreateOrder(ctx, order); err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			for _, status := range tt.statuses {
+				if err := uc.UpdateOrderStatus(ctx, order.ID, status); err != nil {
+					t.Fatalf("UpdateOrderStatus(%s): %v", status, err)
+				}
+			}
+
+			if inventory.stock[1] != tt.wantStock {
+				t.Errorf("stock = %d, want %d", inventory.stock[1], tt.wantStock)
+			}
+			if tt.wantStock == 5 && !reflect.DeepEqual(inventory.released, []entity.OrderItem{{ProductID: 1, Quantity: 2, Price: 2.5}}) {
+				t.Errorf("released %+v, want the order's items once", inventory.released)
+			}
+		})
+	}
+
+	uc := NewOrderUseCase(memory.NewOrderRepository(), &fakeInventory{})
+	if err := uc.UpdateOrderStatus(context.Background(), 404, entity.StatusCancelled); !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("cancelling a missing order = %v, want ErrOrderNotFound", err)
+	}
+}
@@ -0,0 +1,9 @@
+package user
+
+import _ "embed"
+
+// OpenAPI is the REST surface of UserService as described by the HTTP
+// annotations in user.proto.
+//
+//go:embed user.swagger.json
+var OpenAPI []byte
@@ -0,0 +1,186 @@
+// Package app assembles the user service from its configuration. The binary
+// in cmd serves it on real listeners; end-to-end tests serve it in-process.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	sharedconfig "github.com/rrxshxd/assignment1_advProg2/config"
+	"github.com/rrxshxd/assignment1_advProg2/database"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"github.com/rrxshxd/assignment1_advProg2/proto/user"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/config"
+	grpccontroller "github.com/rrxshxd/assignment1_advProg2/user_service/internal/controller/grpc"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/interceptor"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/metrics"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository/memory"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository/postgres"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/usecase"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const healthCheckInterval = 10 * time.Second
+
+type Config = config.Config
+
+// DefaultConfig returns a Config holding only the defaults, for callers that
+// build their configuration in code rather than loading it.
+func DefaultConfig() (*Config, error) {
+	cfg := &Config{}
+	if err := sharedconfig.Defaults(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// App is a wired user service. Neither server is bound to a listener; that
+// is up to the caller.
+type App struct {
+	GRPC    *grpc.Server
+	Health  *health.Server
+	Metrics http.Handler
+
+	db *sql.DB
+}
+
+// New wires the service. With Postgres storage, health status follows
+// database reachability until ctx is cancelled.
+func New(ctx context.Context, cfg *Config, logger *slog.Logger) (*App, error) {
+	app := &App{}
+
+	var (
+		userRepo   repository.UserRepository
+		apiKeyRepo repository.APIKeyRepository
+	)
+	switch cfg.Storage {
+	case config.StorageMemory:
+		logger.Warn("Using in-memory storage, data is lost on restart")
+		userRepo = memory.NewUserRepository()
+		apiKeyRepo = memory.NewAPIKeyRepository()
+	default:
+		db, err := openDatabase(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		app.db = db
+		userRepo = postgres.NewUserRepository(db)
+		apiKeyRepo = postgres.NewAPIKeyRepository(db)
+	}
+
+	passwordPolicy := usecase.PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+	}
+	if cfg.BreachedPasswordsFile != "" {
+		breached, err := usecase.LoadBreachedPasswords(cfg.BreachedPasswordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load breached passwords: %w", err)
+		}
+		passwordPolicy.Breached = breached
+	}
+
+	userUseCase := usecase.NewUserUseCase(userRepo, cfg.JWTSecret, cfg.JWTExpiration, passwordPolicy)
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(apiKeyRepo, userRepo)
+	app.GRPC = grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			metrics.UnaryServerInterceptor(),
+			logging.UnaryServerInterceptor(logger),
+			interceptor.UnaryRecovery(),
+			interceptor.UnaryAuth(userUseCase, grpccontroller.Policies),
+			interceptor.UnaryValidation(grpccontroller.Policies),
+		),
+		grpc.ChainStreamInterceptor(
+			metrics.StreamServerInterceptor(),
+			logging.StreamServerInterceptor(logger),
+			interceptor.StreamRecovery(),
+			interceptor.StreamAuth(userUseCase, grpccontroller.Policies),
+		),
+	)
+	userServer := grpccontroller.NewUserServer(userUseCase, apiKeyUseCase)
+	user.RegisterUserServiceServer(app.GRPC, userServer)
+
+	app.Health = health.NewServer()
+	grpc_health_v1.RegisterHealthServer(app.GRPC, app.Health)
+	if app.db != nil {
+		go watchDatabase(ctx, logger, app.db, app.Health)
+	} else {
+		app.Health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+		app.Health.SetServingStatus(user.UserService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+
+	reflection.Register(app.GRPC)
+
+	// The service only speaks gRPC, so metrics get their own HTTP handler.
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	app.Metrics = mux
+
+	return app, nil
+}
+
+// Close releases the database connections. Stop the gRPC server first.
+func (a *App) Close() error {
+	if a.db == nil {
+		return nil
+	}
+	return a.db.Close()
+}
+
+// openDatabase connects to Postgres and exports the pool statistics.
+func openDatabase(ctx context.Context, cfg *Config) (*sql.DB, error) {
+	pool := database.PoolConfig{
+		MaxOpenConns:     cfg.DBMaxOpenConns,
+		MaxIdleConns:     cfg.DBMaxIdleConns,
+		ConnMaxLifetime:  cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.DBConnMaxIdleTime,
+		StatementTimeout: cfg.DBStatementTimeout,
+	}
+	db, err := database.Connect(ctx, cfg.DatabaseURL, pool, cfg.DBConnectAttempts, cfg.DBConnectRetryDelay)
+	if err != nil {
+		return nil, err
+	}
+	metrics.RegisterDB(db, "users")
+	return db, nil
+}
+
+// watchDatabase keeps the overall and UserService health status in line with
+// database reachability until ctx is cancelled.
+func watchDatabase(ctx context.Context, logger *slog.Logger, db *sql.DB, healthServer *health.Server) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckInterval/2)
+		if err := db.PingContext(pingCtx); err != nil {
+			logger.WarnContext(ctx, "Database health check failed", "error", err)
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+		healthServer.SetServingStatus("", status)
+		healthServer.SetServingStatus(user.UserService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
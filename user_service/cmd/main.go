@@ -1,45 +1,109 @@
 package main
 
 import (
-	"database/sql"
-	_ "github.com/lib/pq"
-	"github.com/rrxshxd/assignment1_advProg2/proto/user"
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"github.com/rrxshxd/assignment1_advProg2/observability/tracing"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/app"
 	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/config"
-	grpccontroller "github.com/rrxshxd/assignment1_advProg2/user_service/internal/controller/grpc"
-	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository/postgres"
-	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/usecase"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
-	"log"
+	"log/slog"
 	"net"
-	"time"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
 
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
 	if err != nil {
-		log.Fatal("Failed to connect to database: %v", err)
+		slog.Error("Invalid log level", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
+	slog.SetDefault(logger)
 
-	userRepo := postgres.NewUserRepository(db)
-	userUseCase := usecase.NewUserUseCase(userRepo, cfg.JWTSecret, time.Duration(cfg.JWTExpirationHours)*time.Hour)
-	grpcServer := grpc.NewServer()
-	userServer := grpccontroller.NewUserServer(userUseCase)
-	user.RegisterUserServiceServer(grpcServer, userServer)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	reflection.Register(grpcServer)
+	shutdownTracing, err := tracing.Setup(ctx, "user_service", tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+		StdoutFile:   cfg.TracingFile,
+	})
+	if err != nil {
+		fatal(logger, "Failed to set up tracing", err)
+	}
+
+	application, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		fatal(logger, "Failed to start user service", err)
+	}
+	defer application.Close()
 
 	lis, err := net.Listen("tcp", ":"+cfg.Port)
 	if err != nil {
-		log.Fatal("Failed to listen: %v", err)
+		fatal(logger, "Failed to listen", err)
+	}
+
+	go func() {
+		logger.Info("User service is running", "port", cfg.Port)
+		if err := application.GRPC.Serve(lis); err != nil {
+			fatal(logger, "Failed to serve gRPC", err)
+		}
+	}()
+
+	metricsServer := &http.Server{Addr: ":" + cfg.MetricsPort, Handler: application.Metrics}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal(logger, "Failed to serve metrics", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("Shutting down, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout.String())
+
+	// Report NOT_SERVING first so health-checking clients stop routing here.
+	application.Health.Shutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	gracefulStop(shutdownCtx, application.GRPC)
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Metrics server shutdown", "error", err)
 	}
 
-	log.Printf("User service is running on port %s", cfg.Port)
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatal("Failed to serve: %v", err)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Tracing shutdown", "error", err)
 	}
+}
 
+// fatal logs err and exits. Deferred calls do not run.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// gracefulStop drains in-flight RPCs, forcing the server closed if ctx
+// expires first.
+func gracefulStop(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+	}
 }
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	sharedconfig "github.com/rrxshxd/assignment1_advProg2/config"
+	"os"
+	"time"
+)
+
+// Storage backends.
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+type Config struct {
+	Port          string        `yaml:"port" default:"50051" validate:"required"`
+	MetricsPort   string        `yaml:"metrics_port" default:"9091" validate:"required"`
+	Storage       string        `yaml:"storage" default:"postgres" validate:"oneof=postgres memory" usage:"repository backend; memory keeps data only until restart"`
+	DatabaseURL   string        `yaml:"database_url" usage:"Postgres connection URL, required for postgres storage"`
+	JWTSecret     string        `yaml:"jwt_secret" validate:"required" usage:"HMAC key for signing access tokens"`
+	JWTExpiration time.Duration `yaml:"jwt_expiration" default:"24h" validate:"min=1m"`
+
+	DBConnectAttempts   int           `yaml:"db_connect_attempts" default:"5" validate:"min=1"`
+	DBConnectRetryDelay time.Duration `yaml:"db_connect_retry_delay" default:"2s"`
+	ShutdownTimeout     time.Duration `yaml:"shutdown_timeout" default:"15s"`
+
+	DBMaxOpenConns     int           `yaml:"db_max_open_conns" default:"25" validate:"min=1"`
+	DBMaxIdleConns     int           `yaml:"db_max_idle_conns" default:"10" validate:"min=0"`
+	DBConnMaxLifetime  time.Duration `yaml:"db_conn_max_lifetime" default:"30m"`
+	DBConnMaxIdleTime  time.Duration `yaml:"db_conn_max_idle_time" default:"5m"`
+	DBStatementTimeout time.Duration `yaml:"db_statement_timeout" default:"30s" usage:"server-side limit per statement, 0 to disable"`
+
+	TracingExporter string `yaml:"tracing_exporter" default:"none" validate:"oneof=none otlp stdout"`
+	OTLPEndpoint    string `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"localhost:4317"`
+	OTLPInsecure    bool   `yaml:"otlp_insecure" env:"OTEL_EXPORTER_OTLP_INSECURE" default:"true"`
+	TracingFile     string `yaml:"tracing_file"`
+
+	LogLevel string `yaml:"log_level" default:"info" validate:"oneof=debug info warn error"`
+
+	PasswordMinLength     int  `yaml:"password_min_length" default:"8" validate:"min=1"`
+	PasswordRequireUpper  bool `yaml:"password_require_upper" default:"true"`
+	PasswordRequireLower  bool `yaml:"password_require_lower" default:"true"`
+	PasswordRequireDigit  bool `yaml:"password_require_digit" default:"true"`
+	PasswordRequireSymbol bool `yaml:"password_require_symbol" default:"false"`
+	// BreachedPasswordsFile is a newline-separated list of passwords that may
+	// not be used. Empty disables the check.
+	BreachedPasswordsFile string `yaml:"breached_passwords_file"`
+}
+
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := sharedconfig.Load(cfg, os.Args[1:]); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	if c.Storage == StoragePostgres && c.DatabaseURL == "" {
+		return errors.New("database_url is required for postgres storage (set DATABASE_URL, DATABASE_URL_FILE, -database-url or \"database_url\" in the config file)")
+	}
+	if c.DBMaxIdleConns > c.DBMaxOpenConns {
+		return errors.New("db_max_idle_conns must not exceed db_max_open_conns")
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/proto/user"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func (s *UserServer) CreateAPIKey(ctx context.Context, req *user.CreateAPIKeyRequest) (*user.CreateAPIKeyResponse, error) {
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		if err := req.ExpiresAt.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		t := req.ExpiresAt.AsTime()
+		expiresAt = &t
+	}
+
+	key, plaintext, err := s.apiKeyUseCase.CreateAPIKey(ctx, uint(req.UserId), req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &user.CreateAPIKeyResponse{
+		ApiKey: apiKeyToProto(key),
+		Key:    plaintext,
+	}, nil
+}
+
+func (s *UserServer) RevokeAPIKey(ctx context.Context, req *user.RevokeAPIKeyRequest) (*user.RevokeAPIKeyResponse, error) {
+	if err := s.apiKeyUseCase.RevokeAPIKey(ctx, uint(req.UserId), uint(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &user.RevokeAPIKeyResponse{}, nil
+}
+
+func (s *UserServer) ListAPIKeys(ctx context.Context, req *user.ListAPIKeysRequest) (*user.ListAPIKeysResponse, error) {
+	keys, err := s.apiKeyUseCase.ListAPIKeys(ctx, uint(req.UserId))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	protoKeys := make([]*user.APIKey, len(keys))
+	for i := range keys {
+		protoKeys[i] = apiKeyToProto(&keys[i])
+	}
+
+	return &user.ListAPIKeysResponse{ApiKeys: protoKeys}, nil
+}
+
+func (s *UserServer) ValidateAPIKey(ctx context.Context, req *user.ValidateAPIKeyRequest) (*user.ValidateAPIKeyResponse, error) {
+	key, err := s.apiKeyUseCase.ValidateAPIKey(ctx, req.Key, req.RequiredScope)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &user.ValidateAPIKeyResponse{
+		Valid:  true,
+		UserId: uint64(key.UserID),
+		KeyId:  uint64(key.ID),
+		Scopes: key.Scopes,
+	}, nil
+}
+
+func apiKeyToProto(key *entity.APIKey) *user.APIKey {
+	return &user.APIKey{
+		Id:         uint64(key.ID),
+		UserId:     uint64(key.UserID),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  optionalTimestamp(key.ExpiresAt),
+		LastUsedAt: optionalTimestamp(key.LastUsedAt),
+		RevokedAt:  optionalTimestamp(key.RevokedAt),
+		CreatedAt:  timestamppb.New(key.CreatedAt),
+	}
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
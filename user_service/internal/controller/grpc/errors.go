@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/usecase"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps use case errors onto gRPC status codes. Anything unexpected
+// becomes Internal without leaking details of the underlying failure.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrUserExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrAPIKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrInvalidCredentials), errors.Is(err, usecase.ErrInvalidAPIKey):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, usecase.ErrAPIKeyScope), errors.Is(err, usecase.ErrScopeNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrInvalidAPIKeyRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrWeakPassword):
+		var violations fieldViolations
+		violations.add("password", err.Error())
+		return violations.err()
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+type fieldViolations []*errdetails.BadRequest_FieldViolation
+
+func (v *fieldViolations) add(field, description string) {
+	*v = append(*v, &errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+}
+
+// err returns nil when there are no violations, otherwise an InvalidArgument
+// status carrying them as errdetails.BadRequest.
+func (v fieldViolations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request")
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: v})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/usecase"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"testing"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{repository.ErrUserExists, codes.AlreadyExists},
+		{repository.ErrUserNotFound, codes.NotFound},
+		{repository.ErrAPIKeyNotFound, codes.NotFound},
+		{usecase.ErrInvalidCredentials, codes.Unauthenticated},
+		{usecase.ErrInvalidAPIKey, codes.Unauthenticated},
+		{usecase.ErrAPIKeyScope, codes.PermissionDenied},
+		{usecase.ErrScopeNotAllowed, codes.PermissionDenied},
+		{usecase.ErrInvalidAPIKeyRequest, codes.InvalidArgument},
+		{usecase.ErrWeakPassword, codes.InvalidArgument},
+		{errors.New("connection refused"), codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			// Use cases wrap the sentinels with context.
+			err := toStatus(fmt.Errorf("%w: for user 7", tt.err))
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("toStatus(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToStatusDetails(t *testing.T) {
+	internal := status.Convert(toStatus(errors.New("dial tcp 10.0.3.7:5432: connection refused")))
+	if strings.Contains(internal.Message(), "10.0.3.7") {
+		t.Errorf("Internal status message %q leaks the underlying error", internal.Message())
+	}
+
+	weak := status.Convert(toStatus(fmt.Errorf("%w: must contain a digit", usecase.ErrWeakPassword)))
+	var fields []string
+	for _, detail := range weak.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	if len(fields) != 1 || fields[0] != "password" {
+		t.Errorf("weak password violations on %v, want password", fields)
+	}
+}
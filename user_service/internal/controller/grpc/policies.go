@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/proto/user"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/interceptor"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Policies configures authentication, ownership and validation per RPC.
+// Field-level validation is declared in user.proto and enforced by the
+// validation interceptor unless a policy skips it.
+//
+// ValidateAPIKey is public because the gateway calls it before a user is
+// known; it never reveals anything beyond the key's own owner and scopes.
+// Health checks are public so load balancers and the gateway can probe.
+var Policies = interceptor.Policies{
+	user.UserService_RegisterUser_FullMethodName:     {Public: true},
+	user.UserService_AuthenticateUser_FullMethodName: {Public: true},
+	user.UserService_ValidateAPIKey_FullMethodName:   {Public: true},
+	grpc_health_v1.Health_Check_FullMethodName:       {Public: true},
+	grpc_health_v1.Health_Watch_FullMethodName:       {Public: true},
+	user.UserService_GetUserProfile_FullMethodName: {
+		Owner: func(req interface{}) uint64 {
+			return req.(*user.GetUserProfileRequest).UserId
+		},
+	},
+	user.UserService_CreateAPIKey_FullMethodName: {
+		Owner: func(req interface{}) uint64 {
+			return req.(*user.CreateAPIKeyRequest).UserId
+		},
+	},
+	user.UserService_RevokeAPIKey_FullMethodName: {
+		Owner: func(req interface{}) uint64 {
+			return req.(*user.RevokeAPIKeyRequest).UserId
+		},
+	},
+	user.UserService_ListAPIKeys_FullMethodName: {
+		Owner: func(req interface{}) uint64 {
+			return req.(*user.ListAPIKeysRequest).UserId
+		},
+	},
+}
@@ -2,31 +2,25 @@ package grpc
 
 import (
 	"context"
-	"errors"
-	"github.com/golang/protobuf/ptypes"
-	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/rrxshxd/assignment1_advProg2/proto/user"
 	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/usecase"
-	"time"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type UserServer struct {
 	user.UnimplementedUserServiceServer
-	userUseCase *usecase.UserUseCase
+	userUseCase   *usecase.UserUseCase
+	apiKeyUseCase *usecase.APIKeyUseCase
 }
 
-func NewUserServer(userUseCase *usecase.UserUseCase) *UserServer {
-	return &UserServer{userUseCase: userUseCase}
+func NewUserServer(userUseCase *usecase.UserUseCase, apiKeyUseCase *usecase.APIKeyUseCase) *UserServer {
+	return &UserServer{userUseCase: userUseCase, apiKeyUseCase: apiKeyUseCase}
 }
 
 func (s *UserServer) RegisterUser(ctx context.Context, req *user.RegisterUserRequest) (*user.UserResponse, error) {
-	if req.Email == "" || req.Username == "" || req.Password == "" {
-		return nil, errors.New("email, username, and password are required")
-	}
-
-	newUser, token, err := s.userUseCase.RegisterUser(req.Email, req.Username, req.Password)
+	newUser, token, err := s.userUseCase.RegisterUser(ctx, req.Email, req.Username, req.Password)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 
 	return &user.UserResponse{
@@ -34,28 +28,20 @@ func (s *UserServer) RegisterUser(ctx context.Context, req *user.RegisterUserReq
 			Id:        uint64(newUser.ID),
 			Email:     newUser.Email,
 			Username:  newUser.Username,
-			CreatedAt: timestampPtrFromTime(newUser.CreatedAt),
+			CreatedAt: timestamppb.New(newUser.CreatedAt),
 		},
 		Token: token,
 	}, nil
 }
 
 func (s *UserServer) AuthenticateUser(ctx context.Context, req *user.AuthRequest) (*user.AuthResponse, error) {
-	if req.Email == "" || req.Password == "" {
-		return &user.AuthResponse{
-			Success:      false,
-			ErrorMessage: "email and password are required",
-		}, nil
-	}
-
-	u, token, err := s.userUseCase.Authenticate(req.Email, req.Password)
+	u, token, err := s.userUseCase.Authenticate(ctx, req.Email, req.Password)
 	if err != nil {
-		return &user.AuthResponse{
-			Success:      false,
-			ErrorMessage: err.Error(),
-		}, nil
+		return nil, toStatus(err)
 	}
 
+	// Success is still populated for clients written against the old
+	// in-band error contract.
 	return &user.AuthResponse{
 		Success: true,
 		Token:   token,
@@ -64,9 +50,9 @@ func (s *UserServer) AuthenticateUser(ctx context.Context, req *user.AuthRequest
 }
 
 func (s *UserServer) GetUserProfile(ctx context.Context, req *user.GetUserProfileRequest) (*user.UserProfile, error) {
-	u, addresses, err := s.userUseCase.GetUserProfile(uint(req.UserId))
+	u, addresses, err := s.userUseCase.GetUserProfile(ctx, uint(req.UserId))
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 
 	protoAddresses := make([]*user.Address, len(addresses))
@@ -86,15 +72,7 @@ func (s *UserServer) GetUserProfile(ctx context.Context, req *user.GetUserProfil
 		Id:        uint64(u.ID),
 		Email:     u.Email,
 		Username:  u.Username,
-		CreatedAt: timestampPtrFromTime(u.CreatedAt),
+		CreatedAt: timestamppb.New(u.CreatedAt),
 		Addresses: protoAddresses,
 	}, nil
 }
-
-func timestampPtrFromTime(t time.Time) *timestamp.Timestamp {
-	ts, err := ptypes.TimestampProto(t)
-	if err != nil {
-		return nil
-	}
-	return ts
-}
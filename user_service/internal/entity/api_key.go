@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// Scopes understood by the gateway. ScopeAll grants every other scope.
+const (
+	ScopeAll            = "*"
+	ScopeInventoryRead  = "inventory:read"
+	ScopeInventoryWrite = "inventory:write"
+	ScopeOrdersRead     = "orders:read"
+	ScopeOrdersWrite    = "orders:write"
+)
+
+// KnownScope reports whether scope is one the gateway enforces.
+func KnownScope(scope string) bool {
+	switch scope {
+	case ScopeAll, ScopeInventoryRead, ScopeInventoryWrite, ScopeOrdersRead, ScopeOrdersWrite:
+		return true
+	}
+	return false
+}
+
+type APIKey struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the key grants scope, either directly or via the
+// wildcard scope "*".
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == "*" || s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -2,15 +2,43 @@ package entity
 
 import "time"
 
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
+// roleScopes lists what each role may do, and therefore the only scopes its
+// access tokens and API keys may carry.
+var roleScopes = map[string][]string{
+	RoleCustomer: {ScopeInventoryRead, ScopeOrdersRead, ScopeOrdersWrite},
+	RoleAdmin:    {ScopeAll, ScopeInventoryRead, ScopeInventoryWrite, ScopeOrdersRead, ScopeOrdersWrite},
+}
+
 type User struct {
 	ID        uint      `json:"id"`
 	Email     string    `json:"email"`
 	Username  string    `json:"username"`
 	Password  string    `json:"-"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Scopes returns the scopes the user's role grants; unknown roles grant none.
+func (u *User) Scopes() []string {
+	return roleScopes[u.Role]
+}
+
+// CanGrant reports whether the user's role includes scope.
+func (u *User) CanGrant(scope string) bool {
+	for _, s := range roleScopes[u.Role] {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 type Address struct {
 	ID         uint      `json:"id"`
 	UserID     uint      `json:"user_id"`
@@ -0,0 +1,92 @@
+package interceptor
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+type TokenParser interface {
+	ParseToken(token string) (uint, error)
+}
+
+type userIDKey struct{}
+
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(uint)
+	return userID, ok
+}
+
+// withUserID records the authenticated user on ctx and its logger.
+func withUserID(ctx context.Context, userID uint) context.Context {
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).With("user_id", userID))
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+func UnaryAuth(parser TokenParser, policies Policies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		policy := policies.lookup(info.FullMethod)
+		if policy.Public {
+			return handler(ctx, req)
+		}
+
+		userID, err := authenticate(ctx, parser)
+		if err != nil {
+			return nil, err
+		}
+
+		if policy.Owner != nil && policy.Owner(req) != uint64(userID) {
+			return nil, status.Error(codes.PermissionDenied, "access to another user's resources is not allowed")
+		}
+
+		return handler(withUserID(ctx, userID), req)
+	}
+}
+
+func StreamAuth(parser TokenParser, policies Policies) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if policies.lookup(info.FullMethod).Public {
+			return handler(srv, ss)
+		}
+
+		userID, err := authenticate(ss.Context(), parser)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withUserID(ss.Context(), userID)})
+	}
+}
+
+func authenticate(ctx context.Context, parser TokenParser) (uint, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return 0, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found || token == "" {
+		return 0, status.Error(codes.Unauthenticated, "authorization must use the Bearer scheme")
+	}
+
+	userID, err := parser.ParseToken(token)
+	if err != nil {
+		return 0, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	return userID, nil
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
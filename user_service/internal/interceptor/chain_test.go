@@ -0,0 +1,266 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/proto/user"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+type tokenParser map[string]uint
+
+func (p tokenParser) ParseToken(token string) (uint, error) {
+	userID, ok := p[token]
+	if !ok {
+		return 0, errors.New("unknown token")
+	}
+	return userID, nil
+}
+
+// testServer answers RegisterUser and GetUserProfile, recording whether a
+// request got past the interceptors. GetUserProfile panics for user 13.
+type testServer struct {
+	user.UnimplementedUserServiceServer
+	called bool
+}
+
+func (s *testServer) RegisterUser(ctx context.Context, req *user.RegisterUserRequest) (*user.UserResponse, error) {
+	s.called = true
+	return &user.UserResponse{}, nil
+}
+
+func (s *testServer) GetUserProfile(ctx context.Context, req *user.GetUserProfileRequest) (*user.UserProfile, error) {
+	s.called = true
+	if req.UserId == 13 {
+		panic("boom")
+	}
+	userID, _ := UserIDFromContext(ctx)
+	return &user.UserProfile{Id: uint64(userID)}, nil
+}
+
+// startChain serves srv behind the same unary chain as the user service and
+// returns a client for it.
+func startChain(t *testing.T, srv user.UserServiceServer, policies Policies) user.UserServiceClient {
+	t.Helper()
+	parser := tokenParser{"alice": 1, "unlucky": 13}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		UnaryRecovery(),
+		UnaryAuth(parser, policies),
+		UnaryValidation(policies),
+	))
+	user.RegisterUserServiceServer(server, srv)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return user.NewUserServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+var testPolicies = Policies{
+	user.UserService_RegisterUser_FullMethodName: {Public: true},
+	user.UserService_GetUserProfile_FullMethodName: {
+		Owner: func(req interface{}) uint64 {
+			return req.(*user.GetUserProfileRequest).UserId
+		},
+	},
+}
+
+func TestUnaryChain(t *testing.T) {
+	validRegistration := &user.RegisterUserRequest{Email: "ada@example.com", Username: "ada", Password: "secret"}
+
+	tests := []struct {
+		name       string
+		call       func(client user.UserServiceClient) error
+		wantCode   codes.Code
+		wantCalled bool
+	}{
+		{
+			name: "public method without a token",
+			call: func(client user.UserServiceClient) error {
+				_, err := client.RegisterUser(context.Background(), validRegistration)
+				return err
+			},
+			wantCode:   codes.OK,
+			wantCalled: true,
+		},
+		{
+			name: "own profile",
+			call: func(client user.UserServiceClient) error {
+				profile, err := client.GetUserProfile(withToken("alice"), &user.GetUserProfileRequest{UserId: 1})
+				if err == nil && profile.Id != 1 {
+					return status.Errorf(codes.Unknown, "handler saw user %d, want 1", profile.Id)
+				}
+				return err
+			},
+			wantCode:   codes.OK,
+			wantCalled: true,
+		},
+		{
+			name: "missing token",
+			call: func(client user.UserServiceClient) error {
+				_, err := client.GetUserProfile(context.Background(), &user.GetUserProfileRequest{UserId: 1})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "invalid token",
+			call: func(client user.UserServiceClient) error {
+				_, err := client.GetUserProfile(withToken("mallory"), &user.GetUserProfileRequest{UserId: 1})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "another user's profile",
+			call: func(client user.UserServiceClient) error {
+				_, err := client.GetUserProfile(withToken("alice"), &user.GetUserProfileRequest{UserId: 2})
+				return err
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "invalid request",
+			call: func(client user.UserServiceClient) error {
+				_, err := client.RegisterUser(context.Background(), &user.RegisterUserRequest{Email: "not-an-email", Username: "ada", Password: "secret"})
+				return err
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "handler panics",
+			call: func(client user.UserServiceClient) error {
+				_, err := client.GetUserProfile(withToken("unlucky"), &user.GetUserProfileRequest{UserId: 13})
+				return err
+			},
+			wantCode:   codes.Internal,
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &testServer{}
+			client := startChain(t, srv, testPolicies)
+
+			err := tt.call(client)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v (%v), want %v", got, err, tt.wantCode)
+			}
+			if srv.called != tt.wantCalled {
+				t.Errorf("handler called = %v, want %v", srv.called, tt.wantCalled)
+			}
+		})
+	}
+}
+
+func TestUnaryValidationReportsFieldViolations(t *testing.T) {
+	client := startChain(t, &testServer{}, testPolicies)
+
+	_, err := client.RegisterUser(context.Background(), &user.RegisterUserRequest{Email: "not-an-email", Username: "a", Password: "secret"})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument", st.Code())
+	}
+	fields := map[string]bool{}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields[violation.Field] = true
+			}
+		}
+	}
+	if !fields["Email"] || !fields["Username"] || len(fields) != 2 {
+		t.Errorf("violations on %v, want Email and Username", fields)
+	}
+}
+
+func TestUnaryValidationPolicy(t *testing.T) {
+	invalid := &user.RegisterUserRequest{Email: "not-an-email", Username: "ada", Password: "secret"}
+	valid := &user.RegisterUserRequest{Email: "ada@example.com", Username: "ada", Password: "secret"}
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		req        *user.RegisterUserRequest
+		wantCode   codes.Code
+		wantCalled bool
+	}{
+		{
+			name:       "skip proto constraints",
+			policy:     Policy{Public: true, SkipValidation: true},
+			req:        invalid,
+			wantCode:   codes.OK,
+			wantCalled: true,
+		},
+		{
+			name: "custom validator rejects",
+			policy: Policy{Public: true, Validate: func(req interface{}) error {
+				return errors.New("registrations are closed")
+			}},
+			req:      valid,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "custom validator keeps its status",
+			policy: Policy{Public: true, Validate: func(req interface{}) error {
+				return status.Error(codes.FailedPrecondition, "registrations are closed")
+			}},
+			req:      valid,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "custom validator runs after the proto constraints",
+			policy: Policy{Public: true, Validate: func(req interface{}) error {
+				return status.Error(codes.FailedPrecondition, "registrations are closed")
+			}},
+			req:      invalid,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "custom validator accepts",
+			policy: Policy{Public: true, Validate: func(req interface{}) error {
+				return nil
+			}},
+			req:        valid,
+			wantCode:   codes.OK,
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &testServer{}
+			client := startChain(t, srv, Policies{user.UserService_RegisterUser_FullMethodName: tt.policy})
+
+			_, err := client.RegisterUser(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v (%v), want %v", got, err, tt.wantCode)
+			}
+			if srv.called != tt.wantCalled {
+				t.Errorf("handler called = %v, want %v", srv.called, tt.wantCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package interceptor
+
+// Policy describes how the interceptor chain treats a single RPC.
+type Policy struct {
+	// Public RPCs skip authentication entirely.
+	Public bool
+	// Owner extracts the user a request acts on; authenticated callers may
+	// only act on themselves. Nil means any authenticated user is allowed.
+	Owner func(req interface{}) uint64
+	// SkipValidation turns off the constraints declared in the proto files,
+	// for RPCs whose handler reports malformed input itself.
+	SkipValidation bool
+	// Validate runs after the proto constraints and rejects requests that
+	// need checks the proto rules cannot express. Errors that are not gRPC
+	// statuses become InvalidArgument.
+	Validate func(req interface{}) error
+}
+
+// Policies maps full gRPC method names to their policy. Methods without an
+// entry require authentication, have no ownership rules and are validated
+// against their proto constraints only.
+type Policies map[string]Policy
+
+func (p Policies) lookup(fullMethod string) Policy {
+	return p[fullMethod]
+}
@@ -0,0 +1,94 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validator is implemented by messages generated with protoc-gen-validate.
+type validator interface {
+	ValidateAll() error
+}
+
+// multiError is implemented by the *MultiError types returned from ValidateAll.
+type multiError interface {
+	AllErrors() []error
+}
+
+// fieldError is implemented by the per-message *ValidationError types.
+type fieldError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// UnaryValidation enforces the constraints declared in the proto files and
+// then any additional validation in the method's policy.
+func UnaryValidation(policies Policies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		policy := policies.lookup(info.FullMethod)
+		if v, ok := req.(validator); ok && !policy.SkipValidation {
+			if err := v.ValidateAll(); err != nil {
+				return nil, validationStatus(err)
+			}
+		}
+		if policy.Validate != nil {
+			if err := policy.Validate(req); err != nil {
+				if _, ok := status.FromError(err); ok {
+					return nil, err
+				}
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func validationStatus(err error) error {
+	badRequest := &errdetails.BadRequest{}
+	collectViolations(badRequest, "", err)
+
+	st, detailErr := status.New(codes.InvalidArgument, "invalid request").WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
+
+// collectViolations flattens nested validation errors into field violations
+// with dotted paths, e.g. "address.city".
+func collectViolations(badRequest *errdetails.BadRequest, prefix string, err error) {
+	var multi multiError
+	if errors.As(err, &multi) {
+		for _, e := range multi.AllErrors() {
+			collectViolations(badRequest, prefix, e)
+		}
+		return
+	}
+
+	var field fieldError
+	if !errors.As(err, &field) {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       prefix,
+			Description: err.Error(),
+		})
+		return
+	}
+
+	path := field.Field()
+	if prefix != "" {
+		path = prefix + "." + path
+	}
+	if cause := field.Cause(); cause != nil {
+		collectViolations(badRequest, path, cause)
+		return
+	}
+	badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+		Field:       path,
+		Description: field.Reason(),
+	})
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	sharedmetrics "github.com/rrxshxd/assignment1_advProg2/observability/metrics"
+	"google.golang.org/grpc"
+	"net/http"
+)
+
+// registry holds this service's metrics instead of the global default, so
+// several services can share a process without their metric names clashing.
+var registry = sharedmetrics.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var (
+	Registrations = factory.NewCounter(prometheus.CounterOpts{
+		Name: "user_registrations_total",
+		Help: "Users registered successfully.",
+	})
+
+	// FailedLogins counts authentication attempts rejected for an unknown
+	// email or a wrong password.
+	FailedLogins = factory.NewCounter(prometheus.CounterOpts{
+		Name: "user_failed_logins_total",
+		Help: "Login attempts rejected for invalid credentials.",
+	})
+)
+
+// RegisterDB exports the connection pool statistics of db. A pool registered
+// under the same name before, by an earlier instance of the service in this
+// process, is replaced.
+func RegisterDB(db *sql.DB, name string) {
+	sharedmetrics.RegisterDB(registry, db, name)
+}
+
+var grpcMetrics = sharedmetrics.NewGRPC(registry)
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return grpcMetrics.UnaryServerInterceptor()
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return grpcMetrics.StreamServerInterceptor()
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"time"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	FindByUserID(ctx context.Context, userID uint) ([]entity.APIKey, error)
+	Revoke(ctx context.Context, id, userID uint) error
+	TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
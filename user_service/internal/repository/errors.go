@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrUserExists     = errors.New("user already exists")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
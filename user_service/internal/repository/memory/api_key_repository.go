@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"sort"
+	"sync"
+	"time"
+)
+
+type apiKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[uint]entity.APIKey
+	nextID uint
+}
+
+// NewAPIKeyRepository returns an empty store that behaves like the Postgres
+// repository: IDs start at 1, FindByUserID returns the newest key first and
+// revoking an already revoked key reports it as not found.
+func NewAPIKeyRepository() repository.APIKeyRepository {
+	return &apiKeyRepository{keys: make(map[uint]entity.APIKey), nextID: 1}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.ID = r.nextID
+	key.CreatedAt = time.Now()
+	r.nextID++
+	r.keys[key.ID] = copyAPIKey(*key)
+
+	return nil
+}
+
+func (r *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			found := copyAPIKey(key)
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: prefix %s", repository.ErrAPIKeyNotFound, prefix)
+}
+
+func (r *apiKeyRepository) FindByUserID(ctx context.Context, userID uint) ([]entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []entity.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, copyAPIKey(key))
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID > keys[j].ID
+	})
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok || key.UserID != userID || key.RevokedAt != nil {
+		return fmt.Errorf("%w: %d", repository.ErrAPIKeyNotFound, id)
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	r.keys[id] = key
+
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.keys[id]; ok {
+		key.LastUsedAt = &usedAt
+		r.keys[id] = key
+	}
+	return nil
+}
+
+// copyAPIKey detaches the scopes and timestamps so callers cannot change the
+// stored key through them.
+func copyAPIKey(key entity.APIKey) entity.APIKey {
+	key.Scopes = append([]string(nil), key.Scopes...)
+	key.ExpiresAt = copyTime(key.ExpiresAt)
+	key.LastUsedAt = copyTime(key.LastUsedAt)
+	key.RevokedAt = copyTime(key.RevokedAt)
+	return key
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	repositorytest.TestAPIKeyRepository(t, func(*testing.T) (repository.APIKeyRepository, uint, uint) {
+		return NewAPIKeyRepository(), 1, 2
+	})
+}
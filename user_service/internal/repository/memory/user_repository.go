@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"sync"
+	"time"
+)
+
+type userRepository struct {
+	mu      sync.RWMutex
+	users   map[uint]entity.User
+	byEmail map[string]uint
+	nextID  uint
+}
+
+// NewUserRepository returns an empty store that behaves like the Postgres
+// repository: IDs start at 1 and emails are unique. Addresses are not
+// managed by this service, so GetAddresses always returns none.
+func NewUserRepository() repository.UserRepository {
+	return &userRepository{
+		users:   make(map[uint]entity.User),
+		byEmail: make(map[string]uint),
+		nextID:  1,
+	}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byEmail[user.Email]; exists {
+		return fmt.Errorf("%w: %s", repository.ErrUserExists, user.Email)
+	}
+
+	now := time.Now()
+	user.ID = r.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.nextID++
+
+	r.users[user.ID] = *user
+	r.byEmail[user.Email] = user.ID
+
+	return nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", repository.ErrUserNotFound, id)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byEmail[email]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", repository.ErrUserNotFound, email)
+	}
+	user := r.users[id]
+	return &user, nil
+}
+
+func (r *userRepository) GetAddresses(ctx context.Context, userID uint) ([]entity.Address, error) {
+	return nil, nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestUserRepository(t *testing.T) {
+	repositorytest.TestUserRepository(t, func(*testing.T) repository.UserRepository {
+		return NewUserRepository()
+	})
+}
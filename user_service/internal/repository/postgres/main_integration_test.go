@@ -0,0 +1,17 @@
+//go:build integration
+
+package postgres
+
+import (
+	"database/sql"
+	"github.com/rrxshxd/assignment1_advProg2/database/databasetest"
+	"os"
+	"testing"
+)
+
+// testDB is shared by every integration test.
+var testDB *sql.DB
+
+func TestMain(m *testing.M) {
+	os.Exit(databasetest.Main(m, "testdata/schema.sql", &testDB))
+}
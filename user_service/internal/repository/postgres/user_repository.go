@@ -1,13 +1,22 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
+	"github.com/rrxshxd/assignment1_advProg2/observability/tracing"
 	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
 	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
 	"time"
 )
 
+var tracer = tracing.NewDBTracer("github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository/postgres")
+
+// uniqueViolation is the Postgres error code for a unique constraint failure.
+const uniqueViolation = "23505"
+
 type userRepository struct {
 	db *sql.DB
 }
@@ -16,39 +25,55 @@ func NewUserRepository(db *sql.DB) repository.UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *entity.User) error {
+func (r *userRepository) Create(ctx context.Context, user *entity.User) (err error) {
+	ctx, span := tracer.Start(ctx, "UserRepository.Create", "INSERT", "users")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `	
-		INSERT INTO users (email, username, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (email, username, password, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
 `
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
 
-	return r.db.QueryRow(query, user.Email, user.Username, user.Password, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	err = r.db.QueryRowContext(ctx, query, user.Email, user.Username, user.Password, user.Role, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return fmt.Errorf("%w: %s", repository.ErrUserExists, user.Email)
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
 }
 
-func (r *userRepository) FindByID(id uint) (*entity.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id uint) (_ *entity.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserRepository.FindByID", "SELECT", "users")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `
-		SELECT id, email, username, password, created_at, updated_at
+		SELECT id, email, username, password, role, created_at, updated_at
 		FROM users
 		WHERE id = $1
 `
 
 	var user entity.User
-	err := r.db.QueryRow(query, id).Scan(
+	err = r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Username,
 		&user.Password,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found: %v", err)
+			return nil, fmt.Errorf("%w: %d", repository.ErrUserNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to find user by ID: %w", err)
 	}
@@ -56,26 +81,30 @@ func (r *userRepository) FindByID(id uint) (*entity.User, error) {
 	return &user, nil
 }
 
-func (r *userRepository) FindByEmail(email string) (*entity.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (_ *entity.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserRepository.FindByEmail", "SELECT", "users")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `
-		SELECT id, email, username, password, created_at, updated_at
+		SELECT id, email, username, password, role, created_at, updated_at
 		FROM users
 		WHERE email = $1
 `
 
 	var user entity.User
-	err := r.db.QueryRow(query, email).Scan(
+	err = r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Username,
 		&user.Password,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found: %v", err)
+			return nil, fmt.Errorf("%w: %s", repository.ErrUserNotFound, email)
 		}
 		return nil, fmt.Errorf("failed to find user by email: %w", err)
 	}
@@ -83,7 +112,10 @@ func (r *userRepository) FindByEmail(email string) (*entity.User, error) {
 	return &user, nil
 }
 
-func (r *userRepository) GetAddresses(userID uint) ([]entity.Address, error) {
+func (r *userRepository) GetAddresses(ctx context.Context, userID uint) (_ []entity.Address, err error) {
+	ctx, span := tracer.Start(ctx, "UserRepository.GetAddresses", "SELECT", "addresses")
+	defer func() { tracer.End(ctx, span, err) }()
+
 	query := `
 		SELECT id, user_id, street, city, state, postal_code, country, is_default, created_at, updated_at 
 		FROM addresses
@@ -91,7 +123,7 @@ func (r *userRepository) GetAddresses(userID uint) ([]entity.Address, error) {
 		ORDER BY is_default DESC, created_at DESC
 `
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get addresses: %w", err)
 	}
@@ -118,7 +150,7 @@ func (r *userRepository) GetAddresses(userID uint) ([]entity.Address, error) {
 		addresses = append(addresses, address)
 	}
 
-	if err := rows.Err(); err != nil {
+	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to get addresses: %w", err)
 	}
 
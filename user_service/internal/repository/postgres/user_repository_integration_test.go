@@ -0,0 +1,34 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/database/databasetest"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestUserRepository(t *testing.T) {
+	repositorytest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+		databasetest.Truncate(t, testDB, "users, addresses, api_keys")
+		return NewUserRepository(testDB)
+	})
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	repositorytest.TestAPIKeyRepository(t, func(t *testing.T) (repository.APIKeyRepository, uint, uint) {
+		databasetest.Truncate(t, testDB, "users, addresses, api_keys")
+		users := NewUserRepository(testDB)
+		a := entity.User{Email: "a@example.com", Username: "a", Password: "x"}
+		b := entity.User{Email: "b@example.com", Username: "b", Password: "x"}
+		for _, user := range []*entity.User{&a, &b} {
+			if err := users.Create(context.Background(), user); err != nil {
+				t.Fatalf("create key owner: %v", err)
+			}
+		}
+		return NewAPIKeyRepository(testDB), a.ID, b.ID
+	})
+}
@@ -0,0 +1,191 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+// APIKeyFactory returns an empty repository together with the IDs of two
+// existing users that keys can belong to. It is called once per subtest.
+type APIKeyFactory func(t *testing.T) (repo repository.APIKeyRepository, userA, userB uint)
+
+// TestAPIKeyRepository runs the APIKeyRepository contract against the
+// repositories returned by newRepo.
+func TestAPIKeyRepository(t *testing.T, newRepo APIKeyFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.APIKeyRepository, userA, userB uint)
+	}{
+		{"CreateAndFind", testAPIKeyCreateAndFind},
+		{"FindMissing", testAPIKeyFindMissing},
+		{"FindByUserID", testAPIKeyFindByUserID},
+		{"Revoke", testAPIKeyRevoke},
+		{"TouchLastUsed", testAPIKeyTouchLastUsed},
+		{"ConcurrentRevoke", testAPIKeyConcurrentRevoke},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, userA, userB := newRepo(t)
+			tt.run(t, repo, userA, userB)
+		})
+	}
+}
+
+func createAPIKey(t *testing.T, repo repository.APIKeyRepository, userID uint, prefix string) *entity.APIKey {
+	t.Helper()
+	key := entity.APIKey{UserID: userID, Name: "key " + prefix, Prefix: prefix, KeyHash: "hash-" + prefix, Scopes: []string{"orders:read"}}
+	if err := repo.Create(context.Background(), &key); err != nil {
+		t.Fatalf("Create(%s): %v", prefix, err)
+	}
+	return &key
+}
+
+func testAPIKeyCreateAndFind(t *testing.T, repo repository.APIKeyRepository, userA, _ uint) {
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Microsecond)
+	key := entity.APIKey{
+		UserID:    userA,
+		Name:      "ci",
+		Prefix:    "pfx_ci",
+		KeyHash:   "hash",
+		Scopes:    []string{"orders:read", "orders:write"},
+		ExpiresAt: &expiresAt,
+	}
+	if err := repo.Create(context.Background(), &key); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if key.ID == 0 || key.CreatedAt.IsZero() {
+		t.Fatalf("Create did not set ID and CreatedAt: %+v", key)
+	}
+
+	got, err := repo.FindByPrefix(context.Background(), "pfx_ci")
+	if err != nil {
+		t.Fatalf("FindByPrefix: %v", err)
+	}
+	if got.ID != key.ID || got.UserID != userA || got.Name != "ci" || got.KeyHash != "hash" {
+		t.Errorf("FindByPrefix = %+v, want the created key", got)
+	}
+	if len(got.Scopes) != 2 || got.Scopes[0] != "orders:read" || got.Scopes[1] != "orders:write" {
+		t.Errorf("Scopes = %q, want [orders:read orders:write]", got.Scopes)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, expiresAt)
+	}
+	if got.LastUsedAt != nil || got.RevokedAt != nil {
+		t.Errorf("new key has LastUsedAt %v and RevokedAt %v, want both unset", got.LastUsedAt, got.RevokedAt)
+	}
+}
+
+func testAPIKeyFindMissing(t *testing.T, repo repository.APIKeyRepository, _, _ uint) {
+	if _, err := repo.FindByPrefix(context.Background(), "missing"); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Errorf("FindByPrefix error = %v, want ErrAPIKeyNotFound", err)
+	}
+}
+
+func testAPIKeyFindByUserID(t *testing.T, repo repository.APIKeyRepository, userA, userB uint) {
+	first := createAPIKey(t, repo, userA, "a1")
+	createAPIKey(t, repo, userB, "b1")
+	second := createAPIKey(t, repo, userA, "a2")
+
+	keys, err := repo.FindByUserID(context.Background(), userA)
+	if err != nil {
+		t.Fatalf("FindByUserID: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != second.ID || keys[1].ID != first.ID {
+		t.Errorf("FindByUserID = %+v, want keys %d and %d, newest first", keys, second.ID, first.ID)
+	}
+
+	none, err := repo.FindByUserID(context.Background(), 404)
+	if err != nil {
+		t.Fatalf("FindByUserID(404): %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("FindByUserID(404) = %+v, want none", none)
+	}
+}
+
+func testAPIKeyRevoke(t *testing.T, repo repository.APIKeyRepository, userA, userB uint) {
+	ctx := context.Background()
+	key := createAPIKey(t, repo, userA, "rv")
+
+	if err := repo.Revoke(ctx, key.ID, userB); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke by another user error = %v, want ErrAPIKeyNotFound", err)
+	}
+	if err := repo.Revoke(ctx, key.ID, userA); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	got, err := repo.FindByPrefix(ctx, "rv")
+	if err != nil {
+		t.Fatalf("FindByPrefix: %v", err)
+	}
+	if got.RevokedAt == nil {
+		t.Error("RevokedAt not set after Revoke")
+	}
+
+	if err := repo.Revoke(ctx, key.ID, userA); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Errorf("second Revoke error = %v, want ErrAPIKeyNotFound", err)
+	}
+	if err := repo.Revoke(ctx, 404, userA); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke(404) error = %v, want ErrAPIKeyNotFound", err)
+	}
+}
+
+func testAPIKeyTouchLastUsed(t *testing.T, repo repository.APIKeyRepository, userA, _ uint) {
+	ctx := context.Background()
+	key := createAPIKey(t, repo, userA, "tu")
+	usedAt := time.Now().Truncate(time.Microsecond)
+
+	if err := repo.TouchLastUsed(ctx, key.ID, usedAt); err != nil {
+		t.Fatalf("TouchLastUsed: %v", err)
+	}
+	got, err := repo.FindByPrefix(ctx, "tu")
+	if err != nil {
+		t.Fatalf("FindByPrefix: %v", err)
+	}
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(usedAt) {
+		t.Errorf("LastUsedAt = %v, want %v", got.LastUsedAt, usedAt)
+	}
+
+	if err := repo.TouchLastUsed(ctx, 404, usedAt); err != nil {
+		t.Errorf("TouchLastUsed(404) = %v, want nil", err)
+	}
+}
+
+func testAPIKeyConcurrentRevoke(t *testing.T, repo repository.APIKeyRepository, userA, _ uint) {
+	const n = 20
+	key := createAPIKey(t, repo, userA, "cr")
+
+	var (
+		wg                sync.WaitGroup
+		mu                sync.Mutex
+		revoked, notFound int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.Revoke(context.Background(), key.ID, userA)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				revoked++
+			case errors.Is(err, repository.ErrAPIKeyNotFound):
+				notFound++
+			default:
+				t.Errorf("Revoke: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if revoked != 1 || notFound != n-1 {
+		t.Errorf("%d revokes succeeded and %d found nothing to revoke, want 1 and %d", revoked, notFound, n-1)
+	}
+}
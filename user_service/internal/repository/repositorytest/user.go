@@ -0,0 +1,177 @@
+// Package repositorytest holds contract tests that every repository
+// implementation must pass, whatever storage it uses.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"sync"
+	"testing"
+)
+
+// UserFactory returns an empty repository. It is called once per subtest.
+type UserFactory func(t *testing.T) repository.UserRepository
+
+// TestUserRepository runs the UserRepository contract against the
+// repositories returned by newRepo.
+func TestUserRepository(t *testing.T, newRepo UserFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.UserRepository)
+	}{
+		{"CreateAndFind", testUserCreateAndFind},
+		{"FindMissing", testUserFindMissing},
+		{"DuplicateEmail", testUserDuplicateEmail},
+		{"GetAddressesEmpty", testUserGetAddressesEmpty},
+		{"ConcurrentCreate", testUserConcurrentCreate},
+		{"ConcurrentDuplicateEmail", testUserConcurrentDuplicateEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func createUser(t *testing.T, repo repository.UserRepository, email string) *entity.User {
+	t.Helper()
+	user := entity.User{Email: email, Username: "user-" + email, Password: "hash-" + email, Role: entity.RoleCustomer}
+	if err := repo.Create(context.Background(), &user); err != nil {
+		t.Fatalf("Create(%s): %v", email, err)
+	}
+	return &user
+}
+
+func testUserCreateAndFind(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	first := createUser(t, repo, "ada@example.com")
+	second := &entity.User{Email: "grace@example.com", Username: "grace", Password: "hash", Role: entity.RoleAdmin}
+	if err := repo.Create(ctx, second); err != nil {
+		t.Fatalf("Create(grace@example.com): %v", err)
+	}
+
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("IDs = %d, %d, want increasing non-zero IDs", first.ID, second.ID)
+	}
+	if first.CreatedAt.IsZero() || first.UpdatedAt.IsZero() {
+		t.Errorf("Create did not set timestamps: %+v", first)
+	}
+
+	byID, err := repo.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if byID.Email != "ada@example.com" || byID.Username != "user-ada@example.com" || byID.Password != "hash-ada@example.com" || byID.Role != entity.RoleCustomer {
+		t.Errorf("FindByID = %+v, want the created user", byID)
+	}
+
+	byEmail, err := repo.FindByEmail(ctx, "grace@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if byEmail.ID != second.ID || byEmail.Role != entity.RoleAdmin {
+		t.Errorf("FindByEmail = %+v, want user %d", byEmail, second.ID)
+	}
+}
+
+func testUserFindMissing(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	if _, err := repo.FindByID(ctx, 404); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByID(404) error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := repo.FindByEmail(ctx, "nobody@example.com"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByEmail error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func testUserDuplicateEmail(t *testing.T, repo repository.UserRepository) {
+	createUser(t, repo, "ada@example.com")
+
+	duplicate := entity.User{Email: "ada@example.com", Username: "other", Password: "x"}
+	if err := repo.Create(context.Background(), &duplicate); !errors.Is(err, repository.ErrUserExists) {
+		t.Errorf("Create with taken email error = %v, want ErrUserExists", err)
+	}
+}
+
+func testUserGetAddressesEmpty(t *testing.T, repo repository.UserRepository) {
+	user := createUser(t, repo, "ada@example.com")
+
+	addresses, err := repo.GetAddresses(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetAddresses: %v", err)
+	}
+	if len(addresses) != 0 {
+		t.Errorf("GetAddresses = %+v, want none", addresses)
+	}
+}
+
+func testUserConcurrentCreate(t *testing.T, repo repository.UserRepository) {
+	const n = 20
+	ids := make(chan uint, n)
+	errs := make(chan error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := entity.User{Email: fmt.Sprintf("user%d@example.com", i), Username: "user", Password: "x"}
+			if err := repo.Create(context.Background(), &user); err != nil {
+				errs <- err
+				return
+			}
+			ids <- user.ID
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Create: %v", err)
+	}
+	seen := make(map[uint]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("ID %d handed out twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func testUserConcurrentDuplicateEmail(t *testing.T, repo repository.UserRepository) {
+	const n = 20
+
+	var (
+		wg              sync.WaitGroup
+		mu              sync.Mutex
+		created, exists int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user := entity.User{Email: "race@example.com", Username: "race", Password: "x"}
+			err := repo.Create(context.Background(), &user)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.Is(err, repository.ErrUserExists):
+				exists++
+			default:
+				t.Errorf("Create: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 || exists != n-1 {
+		t.Errorf("%d creates succeeded and %d hit ErrUserExists, want 1 and %d", created, exists, n-1)
+	}
+}
@@ -1,10 +1,13 @@
 package repository
 
-import "github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+import (
+	"context"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+)
 
 type UserRepository interface {
-	Create(user *entity.User) error
-	FindByID(id uint) (*entity.User, error)
-	FindByEmail(email string) (*entity.User, error)
-	GetAddresses(userID uint) ([]entity.Address, error)
+	Create(ctx context.Context, user *entity.User) error
+	FindByID(ctx context.Context, id uint) (*entity.User, error)
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetAddresses(ctx context.Context, userID uint) ([]entity.Address, error)
 }
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
+	"strings"
+	"time"
+)
+
+const apiKeyTag = "uk"
+
+var (
+	ErrInvalidAPIKey        = errors.New("invalid api key")
+	ErrAPIKeyScope          = errors.New("api key lacks required scope")
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+	ErrScopeNotAllowed      = errors.New("scope not allowed for this user")
+)
+
+type APIKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+}
+
+func NewAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{apiKeyRepo: apiKeyRepo, userRepo: userRepo}
+}
+
+// CreateAPIKey returns the stored key together with its plaintext value, which
+// is not recoverable afterwards. Keys may only carry scopes the owner's role
+// grants.
+func (uc *APIKeyUseCase) CreateAPIKey(ctx context.Context, userID uint, name string, scopes []string, expiresAt *time.Time) (*entity.APIKey, string, error) {
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expiry must be in the future", ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range scopes {
+		if !entity.KnownScope(scope) {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+
+	owner, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, scope := range scopes {
+		if !owner.CanGrant(scope) {
+			return nil, "", fmt.Errorf("%w: %q", ErrScopeNotAllowed, scope)
+		}
+	}
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plaintext := fmt.Sprintf("%s_%s_%s", apiKeyTag, prefix, secret)
+
+	key := &entity.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(plaintext),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := uc.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "api key created", "api_key_id", key.ID, "prefix", key.Prefix, "scopes", key.Scopes)
+
+	return key, plaintext, nil
+}
+
+func (uc *APIKeyUseCase) RevokeAPIKey(ctx context.Context, userID, id uint) error {
+	if err := uc.apiKeyRepo.Revoke(ctx, id, userID); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "api key revoked", "api_key_id", id)
+	return nil
+}
+
+func (uc *APIKeyUseCase) ListAPIKeys(ctx context.Context, userID uint) ([]entity.APIKey, error) {
+	return uc.apiKeyRepo.FindByUserID(ctx, userID)
+}
+
+// ValidateAPIKey resolves a plaintext key and checks that it is active and, if
+// requiredScope is set, that it grants that scope.
+func (uc *APIKeyUseCase) ValidateAPIKey(ctx context.Context, plaintext, requiredScope string) (*entity.APIKey, error) {
+	parts := strings.Split(plaintext, "_")
+	if len(parts) != 3 || parts[0] != apiKeyTag {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := uc.apiKeyRepo.FindByPrefix(ctx, parts[1])
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(plaintext))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: revoked", ErrInvalidAPIKey)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidAPIKey)
+	}
+	if requiredScope != "" && !key.HasScope(requiredScope) {
+		return nil, fmt.Errorf("%w: %q", ErrAPIKeyScope, requiredScope)
+	}
+
+	if err := uc.apiKeyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = &now
+
+	return key, nil
+}
+
+// Keys carry 192 bits of randomness, so a fast hash is sufficient at rest.
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		Breached:      map[string]struct{}{"correcthorse1!": {}},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{name: "strong", password: "Tr0ub4dor&3x"},
+		{name: "too short", password: "Ab1!", want: []string{"at least 10 characters"}},
+		{name: "no uppercase", password: "tr0ub4dor&3x", want: []string{"uppercase letter"}},
+		{name: "no lowercase", password: "TR0UB4DOR&3X", want: []string{"lowercase letter"}},
+		{name: "no digit", password: "Troubador&xx", want: []string{"digit"}},
+		{name: "no symbol", password: "Tr0ub4dor3xx", want: []string{"symbol"}},
+		{name: "breached", password: "correcthorse1!", want: []string{"uppercase letter", "breached"}},
+		{name: "breached in another case", password: "CorrectHorse1!", want: []string{"breached"}},
+		{
+			name:     "every rule",
+			password: "",
+			want:     []string{"at least 10 characters", "uppercase letter", "lowercase letter", "digit", "symbol"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.password)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Check(%q) = %v, want nil", tt.password, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrWeakPassword) {
+				t.Fatalf("Check(%q) = %v, want ErrWeakPassword", tt.password, err)
+			}
+			problems := strings.Split(strings.TrimPrefix(err.Error(), ErrWeakPassword.Error()+": "), ", ")
+			if len(problems) != len(tt.want) {
+				t.Fatalf("Check(%q) lists %q, want %d problems", tt.password, problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadBreachedPasswords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	list := "# top passwords\n\npassword\n  Qwerty123  \n#not-a-password\n\t\nLetMeIn\n"
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+
+	breached, err := LoadBreachedPasswords(path)
+	if err != nil {
+		t.Fatalf("LoadBreachedPasswords: %v", err)
+	}
+	want := map[string]struct{}{"password": {}, "qwerty123": {}, "letmein": {}}
+	if !reflect.DeepEqual(breached, want) {
+		t.Errorf("breached = %v, want %v", breached, want)
+	}
+
+	policy := PasswordPolicy{Breached: breached}
+	if err := policy.Check("LETMEIN"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("Check(LETMEIN) = %v, want a breached password rejected regardless of case", err)
+	}
+
+	if _, err := LoadBreachedPasswords(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadBreachedPasswords(missing file) = nil error, want an error")
+	}
+}
@@ -1,28 +1,39 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rrxshxd/assignment1_advProg2/observability/logging"
 	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/entity"
+	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/metrics"
 	"github.com/rrxshxd/assignment1_advProg2/user_service/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 	"time"
 )
 
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
 type UserUseCase struct {
-	userRepo   repository.UserRepository
-	jwtSecret  string
-	jwtExpires time.Duration
+	userRepo       repository.UserRepository
+	jwtSecret      string
+	jwtExpires     time.Duration
+	passwordPolicy PasswordPolicy
 }
 
-func NewUserUseCase(userRepo repository.UserRepository, jwtSecret string, jwtExpires time.Duration) *UserUseCase {
-	return &UserUseCase{userRepo, jwtSecret, jwtExpires}
+func NewUserUseCase(userRepo repository.UserRepository, jwtSecret string, jwtExpires time.Duration, passwordPolicy PasswordPolicy) *UserUseCase {
+	return &UserUseCase{userRepo, jwtSecret, jwtExpires, passwordPolicy}
 }
 
-func (uc *UserUseCase) RegisterUser(email, username, password string) (*entity.User, string, error) {
-	existingUser, err := uc.userRepo.FindByEmail(email)
+func (uc *UserUseCase) RegisterUser(ctx context.Context, email, username, password string) (*entity.User, string, error) {
+	if err := uc.passwordPolicy.Check(password); err != nil {
+		return nil, "", err
+	}
+
+	existingUser, err := uc.userRepo.FindByEmail(ctx, email)
 	if err == nil && existingUser != nil {
-		return nil, "", fmt.Errorf("user with email %s already exists", email)
+		return nil, "", fmt.Errorf("%w: %s", repository.ErrUserExists, email)
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -34,13 +45,16 @@ func (uc *UserUseCase) RegisterUser(email, username, password string) (*entity.U
 		Email:    email,
 		Username: username,
 		Password: string(hashedPassword),
+		Role:     entity.RoleCustomer,
 	}
 
-	if err := uc.userRepo.Create(user); err != nil {
-		return nil, "", fmt.Errorf("failed to create user: %w", err)
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		return nil, "", err
 	}
+	metrics.Registrations.Inc()
+	logging.FromContext(ctx).InfoContext(ctx, "user registered", "user_id", user.ID)
 
-	token, err := uc.generateToken(user.ID)
+	token, err := uc.generateToken(user)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -48,18 +62,27 @@ func (uc *UserUseCase) RegisterUser(email, username, password string) (*entity.U
 	return user, token, nil
 }
 
-func (uc *UserUseCase) Authenticate(email, password string) (*entity.User, string, error) {
-	user, err := uc.userRepo.FindByEmail(email)
+func (uc *UserUseCase) Authenticate(ctx context.Context, email, password string) (*entity.User, string, error) {
+	// Unknown emails and wrong passwords are reported identically so callers
+	// cannot probe which accounts exist.
+	user, err := uc.userRepo.FindByEmail(ctx, email)
 	if err != nil {
-		return nil, "", fmt.Errorf("invalid email or password: %w", err)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			metrics.FailedLogins.Inc()
+			logging.FromContext(ctx).WarnContext(ctx, "authentication failed", "reason", "unknown email")
+			return nil, "", ErrInvalidCredentials
+		}
+		return nil, "", err
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return nil, "", fmt.Errorf("invalid email or password: %w", err)
+		metrics.FailedLogins.Inc()
+		logging.FromContext(ctx).WarnContext(ctx, "authentication failed", "reason", "wrong password", "user_id", user.ID)
+		return nil, "", ErrInvalidCredentials
 	}
 
-	token, err := uc.generateToken(user.ID)
+	token, err := uc.generateToken(user)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -69,15 +92,15 @@ func (uc *UserUseCase) Authenticate(email, password string) (*entity.User, strin
 	return user, token, nil
 }
 
-func (uc *UserUseCase) GetUserProfile(userID uint) (*entity.User, []entity.Address, error) {
-	user, err := uc.userRepo.FindByID(userID)
+func (uc *UserUseCase) GetUserProfile(ctx context.Context, userID uint) (*entity.User, []entity.Address, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	user.Password = "" // Same as in Aunthenticate function
 
-	addresses, err := uc.userRepo.GetAddresses(userID)
+	addresses, err := uc.userRepo.GetAddresses(ctx, userID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get addresses: %w", err)
 	}
@@ -85,9 +108,30 @@ func (uc *UserUseCase) GetUserProfile(userID uint) (*entity.User, []entity.Addre
 	return user, addresses, nil
 }
 
-func (uc *UserUseCase) generateToken(userID uint) (string, error) {
+// ParseToken verifies a token issued by generateToken and returns its user ID.
+func (uc *UserUseCase) ParseToken(tokenString string) (uint, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(uc.jwtSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, fmt.Errorf("invalid token: %w", err)
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok || userID <= 0 {
+		return 0, fmt.Errorf("invalid token: missing user_id claim")
+	}
+
+	return uint(userID), nil
+}
+
+// generateToken issues an access token carrying the scopes of the user's
+// role, which the gateway enforces without calling back to this service.
+func (uc *UserUseCase) generateToken(user *entity.User) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": userID,
+		"user_id": user.ID,
+		"scopes":  user.Scopes(),
 		"exp":     time.Now().Add(uc.jwtExpires).Unix(),
 	}
 